@@ -0,0 +1,52 @@
+package sdk
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	// 写入时的临时文件后缀
+	tmpFileSuffix = ".tmp"
+	// 临时文件超过该时间未修改视为写入中途崩溃留下的
+	tmpFileMaxAge = time.Minute
+)
+
+// 先写临时文件再重命名，避免进程崩溃时写坏文件
+func writeJSONFile(path string, v interface{}) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	tmp := path + tmpFileSuffix
+	if err := ioutil.WriteFile(tmp, data, 0600); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return nil
+}
+
+// 删除dir中writeJSONFile崩溃遗留的临时文件
+func removeStaleTmpFiles(dir string) error {
+	files, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), tmpFileSuffix) || time.Since(f.ModTime()) <= tmpFileMaxAge {
+			continue
+		}
+		if err := os.Remove(filepath.Join(dir, f.Name())); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,322 @@
+package sdk
+
+import (
+	"context"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// video.del.batch 每次最多操作的视频数
+	MaxDelBatchSize = 50
+	// 批量操作默认并发数
+	DefaultBulkConcurrency = 4
+)
+
+/**
+ * 单个视频的操作结果，Err为nil表示成功
+ */
+type BulkResult struct {
+	VideoId int
+	Err     error
+}
+
+/**
+ * 批量操作结果，按传入顺序排列
+ */
+type BulkReport struct {
+	Results []BulkResult
+}
+
+/**
+ * 操作成功的视频ID
+ * @return []int
+ */
+func (this *BulkReport) Succeeded() []int {
+	ids := make([]int, 0, len(this.Results))
+	for _, r := range this.Results {
+		if r.Err == nil {
+			ids = append(ids, r.VideoId)
+		}
+	}
+	return ids
+}
+
+/**
+ * 操作失败的视频ID，可以用于重试
+ * @return []int
+ */
+func (this *BulkReport) Failed() []int {
+	ids := make([]int, 0)
+	for _, r := range this.Results {
+		if r.Err != nil {
+			ids = append(ids, r.VideoId)
+		}
+	}
+	return ids
+}
+
+/**
+ * 存在失败时返回*BulkError，全部成功返回nil
+ * @return error
+ */
+func (this *BulkReport) Err() error {
+	failed := 0
+	var first error
+	for _, r := range this.Results {
+		if r.Err != nil {
+			if first == nil {
+				first = r.Err
+			}
+			failed++
+		}
+	}
+	if failed == 0 {
+		return nil
+	}
+	return &BulkError{Failed: failed, Total: len(this.Results), First: first}
+}
+
+/**
+ * 批量操作部分失败
+ */
+type BulkError struct {
+	Failed int
+	Total  int
+	First  error
+}
+
+func (this *BulkError) Error() string {
+	return "letv: " + strconv.Itoa(this.Failed) + " of " + strconv.Itoa(this.Total) + " videos failed: " + this.First.Error()
+}
+
+func (this *BulkError) Unwrap() error {
+	return this.First
+}
+
+/**
+ * 批量操作配置，零值使用默认配置
+ */
+type BulkOptions struct {
+	// 并发数
+	Concurrency int
+	// 每秒最多请求数，0表示不限制
+	RateLimit float64
+}
+
+/**
+ * DeleteVideos 配置，零值使用默认配置
+ */
+type DeleteOptions struct {
+	BulkOptions
+	// 批量删除失败后是否逐个调用video.del
+	FallbackSingle bool
+}
+
+/**
+ * 批量删除视频，每50个一批调用video.del.batch
+ * @param  context.Context ctx 请求上下文
+ * @param  []int video_ids 视频ID列表，重复的ID只删除一次
+ * @param  *DeleteOptions opts 可以为nil
+ * @return *BulkReport, error 存在失败时error为*BulkError
+ */
+func (this *LetvCloudV1) DeleteVideos(ctx context.Context, video_ids []int, opts *DeleteOptions) (*BulkReport, error) {
+	o := DeleteOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	ids := uniqueIds(video_ids)
+	report := &BulkReport{Results: make([]BulkResult, len(ids))}
+	batches := (len(ids) + MaxDelBatchSize - 1) / MaxDelBatchSize
+	limiter := newRateLimiter(o.RateLimit)
+	defer limiter.stop()
+
+	runBulk(batches, o.Concurrency, func(b int) {
+		start := b * MaxDelBatchSize
+		end := start + MaxDelBatchSize
+		if end > len(ids) {
+			end = len(ids)
+		}
+		batch := ids[start:end]
+		err := limiter.wait(ctx)
+		if err == nil {
+			err = this.VideoDelBatch(ctx, batch)
+		}
+		for i, id := range batch {
+			result := BulkResult{VideoId: id, Err: err}
+			if err != nil && o.FallbackSingle && ctx.Err() == nil {
+				if result.Err = limiter.wait(ctx); result.Err == nil {
+					result.Err = this.VideoDel(ctx, id)
+				}
+			}
+			report.Results[start+i] = result
+		}
+	})
+	return report, report.Err()
+}
+
+/**
+ * 批量暂停视频，可以使用 report.Failed() 重试失败的视频
+ * @param  context.Context ctx 请求上下文
+ * @param  []int video_ids 视频ID列表，重复的ID只操作一次
+ * @param  *BulkOptions opts 可以为nil
+ * @return *BulkReport, error 存在失败时error为*BulkError
+ */
+func (this *LetvCloudV1) PauseVideos(ctx context.Context, video_ids []int, opts *BulkOptions) (*BulkReport, error) {
+	return this.bulkEach(ctx, video_ids, opts, this.VideoPause)
+}
+
+/**
+ * 批量恢复视频，可以使用 report.Failed() 重试失败的视频
+ * @param  context.Context ctx 请求上下文
+ * @param  []int video_ids 视频ID列表，重复的ID只操作一次
+ * @param  *BulkOptions opts 可以为nil
+ * @return *BulkReport, error 存在失败时error为*BulkError
+ */
+func (this *LetvCloudV1) RestoreVideos(ctx context.Context, video_ids []int, opts *BulkOptions) (*BulkReport, error) {
+	return this.bulkEach(ctx, video_ids, opts, this.VideoRestore)
+}
+
+/**
+ * 暂停符合查询条件的所有视频
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoListRequest filter 查询条件
+ * @param  *BulkOptions opts 可以为nil
+ * @return *BulkReport, error 查询失败或存在失败时返回error
+ */
+func (this *LetvCloudV1) PauseMatching(ctx context.Context, filter VideoListRequest, opts *BulkOptions) (*BulkReport, error) {
+	ids, err := this.listVideoIds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return this.PauseVideos(ctx, ids, opts)
+}
+
+/**
+ * 恢复符合查询条件的所有视频
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoListRequest filter 查询条件
+ * @param  *BulkOptions opts 可以为nil
+ * @return *BulkReport, error 查询失败或存在失败时返回error
+ */
+func (this *LetvCloudV1) RestoreMatching(ctx context.Context, filter VideoListRequest, opts *BulkOptions) (*BulkReport, error) {
+	ids, err := this.listVideoIds(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return this.RestoreVideos(ctx, ids, opts)
+}
+
+// 先取出所有视频ID，避免操作过程中列表变化影响分页
+func (this *LetvCloudV1) listVideoIds(ctx context.Context, filter VideoListRequest) ([]int, error) {
+	ids := make([]int, 0)
+	it := this.ListVideos(ctx, filter)
+	for it.Next() {
+		ids = append(ids, it.Video().VideoId)
+	}
+	return ids, it.Err()
+}
+
+// 对每个视频并发执行op
+func (this *LetvCloudV1) bulkEach(ctx context.Context, video_ids []int, opts *BulkOptions, op func(context.Context, int) error) (*BulkReport, error) {
+	o := BulkOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	ids := uniqueIds(video_ids)
+	report := &BulkReport{Results: make([]BulkResult, len(ids))}
+	limiter := newRateLimiter(o.RateLimit)
+	defer limiter.stop()
+
+	runBulk(len(ids), o.Concurrency, func(i int) {
+		err := limiter.wait(ctx)
+		if err == nil {
+			err = op(ctx, ids[i])
+		}
+		report.Results[i] = BulkResult{VideoId: ids[i], Err: err}
+	})
+	return report, report.Err()
+}
+
+/**
+ * 并发执行n个任务，每个任务都会被调用以记录结果
+ * @param  int n 任务数
+ * @param  int concurrency 并发数，小于等于0时使用默认值
+ * @param  func(int) fn 执行第i个任务，需要自行处理ctx取消
+ */
+func runBulk(n, concurrency int, fn func(i int)) {
+	if concurrency <= 0 {
+		concurrency = DefaultBulkConcurrency
+	}
+	if concurrency > n {
+		concurrency = n
+	}
+	tasks := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range tasks {
+				fn(i)
+			}
+		}()
+	}
+	for i := 0; i < n; i++ {
+		tasks <- i
+	}
+	close(tasks)
+	wg.Wait()
+}
+
+// 去除重复ID，保持原有顺序
+func uniqueIds(video_ids []int) []int {
+	ids := make([]int, 0, len(video_ids))
+	seen := make(map[int]bool)
+	for _, id := range video_ids {
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// 按固定间隔放行请求，多个协程共用
+type rateLimiter struct {
+	ticker *time.Ticker
+}
+
+// rate小于等于0或间隔不足1ns时返回nil，表示不限制
+func newRateLimiter(rate float64) *rateLimiter {
+	if rate <= 0 {
+		return nil
+	}
+	interval := time.Duration(float64(time.Second) / rate)
+	if interval <= 0 {
+		return nil
+	}
+	return &rateLimiter{ticker: time.NewTicker(interval)}
+}
+
+func (this *rateLimiter) wait(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if this == nil {
+		return nil
+	}
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-this.ticker.C:
+		return nil
+	}
+}
+
+func (this *rateLimiter) stop() {
+	if this != nil {
+		this.ticker.Stop()
+	}
+}
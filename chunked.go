@@ -0,0 +1,185 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+)
+
+const (
+	// 默认分片大小
+	DefaultChunkSize int64 = 4 << 20
+	// 单个分片默认最大重试次数
+	DefaultChunkRetries = 3
+	// 分片重试默认等待时间，每次重试翻倍
+	DefaultChunkRetryDelay = time.Second
+)
+
+/**
+ * 分片上传配置，零值使用默认配置
+ */
+type ChunkOptions struct {
+	// 分片大小，单位为字节
+	ChunkSize int64
+	// 单个分片最大重试次数，小于0表示不重试
+	MaxRetries int
+	// 首次重试等待时间
+	RetryDelay time.Duration
+	// 每个分片上传成功后调用
+	OnChunk func(ChunkInfo)
+	// 上传进度回调，统计整个文件的进度
+	OnProgress TransferFunc
+}
+
+/**
+ * 已上传成功的分片
+ * Offset+Size == Total 表示全部上传完成
+ */
+type ChunkInfo struct {
+	Index    int
+	Offset   int64
+	Size     int64
+	Total    int64
+	Attempts int
+}
+
+func (this ChunkInfo) Done() bool {
+	return this.Offset+this.Size >= this.Total
+}
+
+func (this *ChunkOptions) withDefaults() ChunkOptions {
+	opts := ChunkOptions{}
+	if this != nil {
+		opts = *this
+	}
+	if opts.ChunkSize <= 0 {
+		opts.ChunkSize = DefaultChunkSize
+	}
+	if opts.MaxRetries == 0 {
+		opts.MaxRetries = DefaultChunkRetries
+	}
+	if opts.MaxRetries < 0 {
+		opts.MaxRetries = 0
+	}
+	if opts.RetryDelay <= 0 {
+		opts.RetryDelay = DefaultChunkRetryDelay
+	}
+	return opts
+}
+
+/**
+ * 分片上传本地文件：初始化时指定uploadtype=1，然后按分片上传
+ * @param  context.Context ctx 请求上下文
+ * @param  string video_name 视频名称
+ * @param  string video_file 文件绝对路径
+ * @param  *UploadInitOptions init 初始化可选参数，可以为nil，file_size和uploadtype自动填写
+ * @param  *ChunkOptions opts 分片配置，可以为nil
+ * @return *UploadInitResult, error
+ */
+func (this *LetvCloudV1) UploadFileChunked(ctx context.Context, video_name, video_file string, init *UploadInitOptions, opts *ChunkOptions) (*UploadInitResult, error) {
+	fh, err := os.Open(video_file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.videoUploadInit_(ctx, video_name, fileInitOptions(init, info.Size(), UPLOAD_CHUNKED))
+	if err != nil {
+		return nil, err
+	}
+	if result.UploadType != UPLOAD_CHUNKED {
+		// 服务端不支持分片时整体上传
+		meter := newTransferMeter(opts.withDefaults().OnProgress, info.Size())
+		return result, this.upload(ctx, result.UploadUrl, filepath.Base(video_file), meter.reader(fh, 0), info.Size(), nil)
+	}
+	err = this.UploadChunks(ctx, fh, 0, info.Size(), filepath.Base(video_file), result.UploadUrl, opts)
+	return result, err
+}
+
+/**
+ * 视频分片上传 (web方式)，上传地址须由uploadtype=1的video.upload.init或video.upload.resume返回
+ * @param  context.Context ctx 请求上下文
+ * @param  string video_file 文件绝对路径
+ * @param  string upload_url 视频上传地址
+ * @param  *ChunkOptions opts 分片配置，可以为nil
+ * @return error
+ */
+func (this *LetvCloudV1) VideoUploadChunked(ctx context.Context, video_file, upload_url string, opts *ChunkOptions) error {
+	fh, err := os.Open(video_file)
+	if err != nil {
+		return err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return err
+	}
+	return this.UploadChunks(ctx, fh, 0, info.Size(), filepath.Base(video_file), upload_url, opts)
+}
+
+/**
+ * 从offset开始分片上传，每个分片失败后单独重试
+ * @param  context.Context ctx 请求上下文
+ * @param  io.ReaderAt r 视频内容
+ * @param  int64 offset 开始上传的位置，断点续传时为已上传大小
+ * @param  int64 size 视频总大小
+ * @param  string name 视频文件名
+ * @param  string upload_url 视频上传地址
+ * @param  *ChunkOptions opts 分片配置，可以为nil
+ * @return error
+ */
+func (this *LetvCloudV1) UploadChunks(ctx context.Context, r io.ReaderAt, offset, size int64, name, upload_url string, opts *ChunkOptions) error {
+	if offset < 0 || offset > size {
+		return errors.New("letv: chunk offset out of range")
+	}
+	o := opts.withDefaults()
+	meter := newTransferMeter(o.OnProgress, size)
+	for index := 0; offset < size; index++ {
+		n := o.ChunkSize
+		if offset+n > size {
+			n = size - offset
+		}
+		attempts, err := this.uploadChunk(ctx, r, offset, n, size, name, upload_url, o, meter)
+		if err != nil {
+			return err
+		}
+		if o.OnChunk != nil {
+			o.OnChunk(ChunkInfo{Index: index, Offset: offset, Size: n, Total: size, Attempts: attempts})
+		}
+		offset += n
+	}
+	return nil
+}
+
+// 上传单个分片，失败时按退避时间重试
+func (this *LetvCloudV1) uploadChunk(ctx context.Context, r io.ReaderAt, offset, n, size int64, name, upload_url string, o ChunkOptions, meter *transferMeter) (int, error) {
+	header := http.Header{}
+	header.Set("Content-Range", "bytes "+strconv.FormatInt(offset, 10)+"-"+strconv.FormatInt(offset+n-1, 10)+"/"+strconv.FormatInt(size, 10))
+
+	delay := o.RetryDelay
+	for attempt := 1; ; attempt++ {
+		err := this.upload(ctx, upload_url, name, meter.reader(io.NewSectionReader(r, offset, n), offset), n, header)
+		if err == nil {
+			return attempt, nil
+		}
+		if attempt > o.MaxRetries || !isRetryable(ctx, err) {
+			return attempt, err
+		}
+		select {
+		case <-ctx.Done():
+			return attempt, ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
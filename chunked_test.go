@@ -0,0 +1,197 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟分片上传地址，按Content-Range记录每次请求，fail返回该次请求应返回的错误响应
+type fakeUpload struct {
+	mu       sync.Mutex
+	attempts map[string]int
+	ranges   []string
+	data     map[string][]byte
+}
+
+func newFakeUpload(t *testing.T, fail func(contentRange string, attempt int) (int, string)) (*fakeUpload, string) {
+	f := &fakeUpload{attempts: make(map[string]int), data: make(map[string][]byte)}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		cr := r.Header.Get("Content-Range")
+		file, _, err := r.FormFile(uploadFieldName)
+		if err != nil {
+			t.Errorf("read form file: %v", err)
+			return
+		}
+		body, _ := ioutil.ReadAll(file)
+
+		f.mu.Lock()
+		f.attempts[cr]++
+		attempt := f.attempts[cr]
+		f.ranges = append(f.ranges, cr)
+		f.mu.Unlock()
+
+		if status, resp := fail(cr, attempt); status != 0 {
+			w.WriteHeader(status)
+			w.Write([]byte(resp))
+			return
+		}
+		f.mu.Lock()
+		f.data[cr] = body
+		f.mu.Unlock()
+		w.Write([]byte(`{"code":0}`))
+	}))
+	t.Cleanup(server.Close)
+	return f, server.URL
+}
+
+func TestUploadChunksRetry(t *testing.T) {
+	content := []byte("0123456789")
+	noFail := func(string, int) (int, string) { return 0, "" }
+
+	tests := []struct {
+		name       string
+		fail       func(cr string, attempt int) (int, string)
+		retries    int
+		wantErr    bool
+		wantChunks []ChunkInfo
+		// 第二个分片的请求次数
+		wantMiddle int
+		// 至少等待的退避时间
+		minElapsed time.Duration
+	}{
+		{
+			name:    "no failures",
+			fail:    noFail,
+			retries: 2,
+			wantChunks: []ChunkInfo{
+				{Index: 0, Offset: 0, Size: 4, Total: 10, Attempts: 1},
+				{Index: 1, Offset: 4, Size: 4, Total: 10, Attempts: 1},
+				{Index: 2, Offset: 8, Size: 2, Total: 10, Attempts: 1},
+			},
+			wantMiddle: 1,
+		},
+		{
+			name: "retries transient failures with backoff",
+			fail: func(cr string, attempt int) (int, string) {
+				if cr == "bytes 4-7/10" && attempt <= 2 {
+					return http.StatusBadGateway, ""
+				}
+				return 0, ""
+			},
+			retries: 2,
+			wantChunks: []ChunkInfo{
+				{Index: 0, Offset: 0, Size: 4, Total: 10, Attempts: 1},
+				{Index: 1, Offset: 4, Size: 4, Total: 10, Attempts: 3},
+				{Index: 2, Offset: 8, Size: 2, Total: 10, Attempts: 1},
+			},
+			wantMiddle: 3,
+			minElapsed: 30 * time.Millisecond,
+		},
+		{
+			name: "gives up after max retries",
+			fail: func(cr string, attempt int) (int, string) {
+				if cr == "bytes 4-7/10" {
+					return http.StatusInternalServerError, ""
+				}
+				return 0, ""
+			},
+			retries: 2,
+			wantErr: true,
+			wantChunks: []ChunkInfo{
+				{Index: 0, Offset: 0, Size: 4, Total: 10, Attempts: 1},
+			},
+			wantMiddle: 3,
+		},
+		{
+			name: "does not retry api errors",
+			fail: func(cr string, attempt int) (int, string) {
+				if cr == "bytes 4-7/10" {
+					return http.StatusOK, `{"code":1,"message":"rejected"}`
+				}
+				return 0, ""
+			},
+			retries: 2,
+			wantErr: true,
+			wantChunks: []ChunkInfo{
+				{Index: 0, Offset: 0, Size: 4, Total: 10, Attempts: 1},
+			},
+			wantMiddle: 1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, uploadUrl := newFakeUpload(t, tt.fail)
+			c := NewLetvCloudV1(testUnique, testSecret)
+			chunks := make([]ChunkInfo, 0)
+			opts := &ChunkOptions{
+				ChunkSize:  4,
+				MaxRetries: tt.retries,
+				RetryDelay: 10 * time.Millisecond,
+				OnChunk:    func(info ChunkInfo) { chunks = append(chunks, info) },
+			}
+
+			start := time.Now()
+			err := c.UploadChunks(context.Background(), bytes.NewReader(content), 0, int64(len(content)), "a.mp4", uploadUrl, opts)
+			elapsed := time.Since(start)
+
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if len(chunks) != len(tt.wantChunks) {
+				t.Fatalf("chunks = %+v, want %+v", chunks, tt.wantChunks)
+			}
+			for i := range chunks {
+				if chunks[i] != tt.wantChunks[i] {
+					t.Errorf("chunk %d = %+v, want %+v", i, chunks[i], tt.wantChunks[i])
+				}
+			}
+			if got := f.attempts["bytes 4-7/10"]; got != tt.wantMiddle {
+				t.Errorf("middle chunk attempts = %d, want %d", got, tt.wantMiddle)
+			}
+			if elapsed < tt.minElapsed {
+				t.Errorf("elapsed %v, want at least %v of backoff", elapsed, tt.minElapsed)
+			}
+			if !tt.wantErr {
+				got := append(append(f.data["bytes 0-3/10"], f.data["bytes 4-7/10"]...), f.data["bytes 8-9/10"]...)
+				if !bytes.Equal(got, content) {
+					t.Errorf("uploaded %q, want %q", got, content)
+				}
+			}
+		})
+	}
+}
+
+func TestUploadChunksResumesFromOffset(t *testing.T) {
+	f, uploadUrl := newFakeUpload(t, func(string, int) (int, string) { return 0, "" })
+	c := NewLetvCloudV1(testUnique, testSecret)
+	content := []byte("0123456789")
+	err := c.UploadChunks(context.Background(), bytes.NewReader(content), 6, int64(len(content)), "a.mp4", uploadUrl, &ChunkOptions{ChunkSize: 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(f.ranges) != 1 || f.ranges[0] != "bytes 6-9/10" {
+		t.Errorf("ranges = %v, want [bytes 6-9/10]", f.ranges)
+	}
+	if string(f.data["bytes 6-9/10"]) != "6789" {
+		t.Errorf("uploaded %q", f.data["bytes 6-9/10"])
+	}
+}
+
+func TestUploadChunksStopsOnCancel(t *testing.T) {
+	_, uploadUrl := newFakeUpload(t, func(string, int) (int, string) { return http.StatusServiceUnavailable, "" })
+	c := NewLetvCloudV1(testUnique, testSecret)
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	err := c.UploadChunks(ctx, bytes.NewReader([]byte("0123")), 0, 4, "a.mp4", uploadUrl, &ChunkOptions{MaxRetries: 10, RetryDelay: time.Second})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+}
@@ -0,0 +1,140 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"math"
+	"sort"
+)
+
+const (
+	// 每张图最多采样的像素数
+	coverMaxSamples = 10000
+	// 亮度标准差低于该值视为纯色图
+	coverBlankContrast = 0.02
+	// 平均亮度低于该值视为黑屏
+	coverDarkBrightness = 0.06
+	// 平均亮度高于该值视为白屏
+	coverLightBrightness = 0.96
+)
+
+// 没有可用的封面
+var ErrNoCover = errors.New("letv: no usable cover")
+
+/**
+ * 封面候选图评分
+ * Brightness 平均亮度，0-1之间；Contrast 亮度标准差；Blank 为true表示黑屏、白屏或纯色图
+ */
+type CoverCandidate struct {
+	Screenshot
+	Brightness float64
+	Contrast   float64
+	Blank      bool
+	Score      float64
+	Err        error
+}
+
+/**
+ * 计算图片的亮度、对比度和评分，评分越高越适合作为封面，纯色图评分为0
+ * @param  image.Image img
+ * @return CoverCandidate
+ */
+func ScoreImage(img image.Image) CoverCandidate {
+	bounds := img.Bounds()
+	w, h := bounds.Dx(), bounds.Dy()
+	if w <= 0 || h <= 0 {
+		return CoverCandidate{Blank: true}
+	}
+	step := int(math.Sqrt(float64(w*h) / coverMaxSamples))
+	if step < 1 {
+		step = 1
+	}
+
+	var sum, sumSq float64
+	n := 0
+	for y := bounds.Min.Y; y < bounds.Max.Y; y += step {
+		for x := bounds.Min.X; x < bounds.Max.X; x += step {
+			r, g, b, _ := img.At(x, y).RGBA()
+			l := (0.299*float64(r) + 0.587*float64(g) + 0.114*float64(b)) / 0xffff
+			sum += l
+			sumSq += l * l
+			n++
+		}
+	}
+	mean := sum / float64(n)
+	variance := sumSq/float64(n) - mean*mean
+	if variance < 0 {
+		variance = 0
+	}
+	c := CoverCandidate{Brightness: mean, Contrast: math.Sqrt(variance)}
+	c.Blank = c.Contrast < coverBlankContrast || mean < coverDarkBrightness || mean > coverLightBrightness
+	if !c.Blank {
+		// 亮度越接近中间值越好，对比度越高越好
+		brightness := 1 - math.Abs(mean-0.5)*2
+		contrast := math.Min(c.Contrast/0.25, 1)
+		c.Score = 0.4*brightness + 0.6*contrast
+	}
+	return c
+}
+
+/**
+ * 下载并评分截图，按评分从高到低排序，下载或解码失败的候选Err不为nil并排在最后
+ * @param  context.Context ctx 请求上下文
+ * @param  []Screenshot shots 截图
+ * @param  int concurrency 并发数，小于等于0时使用默认值
+ * @return []CoverCandidate
+ */
+func (this *LetvCloudV1) RankCovers(ctx context.Context, shots []Screenshot, concurrency int) []CoverCandidate {
+	candidates := make([]CoverCandidate, len(shots))
+	runBulk(len(shots), concurrency, func(i int) {
+		candidates[i] = this.scoreScreenshot(ctx, shots[i])
+	})
+	sort.SliceStable(candidates, func(i, j int) bool {
+		if (candidates[i].Err == nil) != (candidates[j].Err == nil) {
+			return candidates[i].Err == nil
+		}
+		return candidates[i].Score > candidates[j].Score
+	})
+	return candidates
+}
+
+/**
+ * 从指定尺寸的8张截图中选出最适合作为封面的一张，不会选择黑屏、白屏或纯色图
+ * @param  context.Context ctx 请求上下文
+ * @param  int video_id 视频ID
+ * @param  ImageSize size 截图尺寸
+ * @return *CoverCandidate, error 没有可用截图时返回ErrNoCover
+ */
+func (this *LetvCloudV1) PickCover(ctx context.Context, video_id int, size ImageSize) (*CoverCandidate, error) {
+	shots, err := this.ImageGet(ctx, video_id, size)
+	if err != nil {
+		return nil, err
+	}
+	candidates := this.RankCovers(ctx, shots, ScreenshotsPerSize)
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	if len(candidates) == 0 || candidates[0].Err != nil || candidates[0].Blank {
+		return nil, ErrNoCover
+	}
+	return &candidates[0], nil
+}
+
+func (this *LetvCloudV1) scoreScreenshot(ctx context.Context, shot Screenshot) CoverCandidate {
+	buf := &bytes.Buffer{}
+	if _, err := this.DownloadScreenshot(ctx, shot, buf); err != nil {
+		return CoverCandidate{Screenshot: shot, Err: err}
+	}
+	img, _, err := image.Decode(buf)
+	if err != nil {
+		return CoverCandidate{Screenshot: shot, Err: err}
+	}
+	c := ScoreImage(img)
+	c.Screenshot = shot
+	return c
+}
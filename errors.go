@@ -0,0 +1,154 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"sync"
+)
+
+// 乐视云SDK错误类型
+
+/**
+ * 接口返回错误，code非0时返回，Code和Message为接口原样返回
+ * 可以使用 errors.Is(err, &APIError{Code: code}) 判断具体错误码，
+ * 已通过 RegisterErrorCode 登记的错误码也可以用 errors.Is(err, ErrVideoNotFound) 等判断
+ */
+type APIError struct {
+	Api     string
+	Code    int
+	Message string
+}
+
+func (this *APIError) Error() string {
+	msg := "letv: "
+	if len(this.Api) > 0 {
+		msg += this.Api + ": "
+	}
+	msg += "code " + strconv.Itoa(this.Code)
+	if len(this.Message) > 0 {
+		msg += ": " + this.Message
+	}
+	return msg
+}
+
+// 错误码相同，或错误码已登记为target，即认为是同一错误
+func (this *APIError) Is(target error) bool {
+	if t, ok := target.(*APIError); ok {
+		return t.Code == this.Code
+	}
+	known := lookupErrorCode(this.Code)
+	return known != nil && known == target
+}
+
+/**
+ * 网络错误：连接失败、读取失败或HTTP状态码异常
+ * StatusCode 为0表示未收到HTTP响应
+ */
+type TransportError struct {
+	Op         string
+	Url        string
+	StatusCode int
+	Err        error
+}
+
+func (this *TransportError) Error() string {
+	return fmt.Sprintf("letv: %s %s: %v", this.Op, this.Url, this.Err)
+}
+
+func (this *TransportError) Unwrap() error {
+	return this.Err
+}
+
+/**
+ * 返回内容解析失败，Body为接口原始返回
+ */
+type DecodeError struct {
+	Body []byte
+	Err  error
+}
+
+func (this *DecodeError) Error() string {
+	return "letv: decode response: " + this.Err.Error()
+}
+
+func (this *DecodeError) Unwrap() error {
+	return this.Err
+}
+
+/**
+ * 构造签名失败，业务参数值必须为string
+ */
+type SignError struct {
+	Key string
+}
+
+func (this *SignError) Error() string {
+	return "letv: sign: param " + strconv.Quote(this.Key) + " is not a string"
+}
+
+/**
+ * 请求参数校验失败，不会发送请求
+ */
+type ValidationError struct {
+	Field  string
+	Reason string
+}
+
+func (this *ValidationError) Error() string {
+	return "letv: invalid " + this.Field + ": " + this.Reason
+}
+
+// 返回内容为空
+var ErrEmptyResponse = errors.New("empty response")
+
+/**
+ * 已知错误，使用 errors.Is(err, ErrVideoNotFound) 判断
+ * 接口文档只约定code为0表示成功，其它值的含义见message，没有公开的错误码列表，
+ * 因此SDK不内置错误码，请按乐视云提供的错误码说明用 RegisterErrorCode 登记
+ * VideoGet 在接口未返回视频信息时直接返回 ErrVideoNotFound
+ */
+var (
+	ErrVideoNotFound = errors.New("letv: video not found")
+	ErrBadSign       = errors.New("letv: bad sign")
+	ErrInvalidToken  = errors.New("letv: invalid upload token")
+)
+
+// 已登记的错误码
+var (
+	errorCodesMu sync.RWMutex
+	errorCodes   = map[int]error{}
+)
+
+/**
+ * 登记错误码，之后该错误码的APIError满足 errors.Is(err, target)
+ * @param  int code 接口返回的错误码
+ * @param  error target 已知错误，如ErrVideoNotFound
+ */
+func RegisterErrorCode(code int, target error) {
+	errorCodesMu.Lock()
+	defer errorCodesMu.Unlock()
+	errorCodes[code] = target
+}
+
+// 错误码对应的已知错误，未登记时返回nil
+func lookupErrorCode(code int) error {
+	errorCodesMu.RLock()
+	defer errorCodesMu.RUnlock()
+	return errorCodes[code]
+}
+
+// 网络错误和返回内容异常可以重试，接口返回的业务错误不重试
+func isRetryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var transportErr *TransportError
+	if errors.As(err, &transportErr) {
+		return transportErr.StatusCode == 0 || transportErr.StatusCode >= 500 || transportErr.StatusCode == http.StatusTooManyRequests
+	}
+	var decodeErr *DecodeError
+	return errors.As(err, &decodeErr)
+}
@@ -0,0 +1,319 @@
+package sdk
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"strconv"
+	"sync"
+)
+
+// Excel 识别 UTF-8 编码需要的 BOM
+const utf8BOM = "\xef\xbb\xbf"
+
+/**
+ * 视频名称缓存，统计数据没有返回视频名称时通过video.get查询，已删除的视频名称为空
+ * 可以在多个协程中使用
+ */
+type VideoNames struct {
+	client *LetvCloudV1
+	mu     sync.Mutex
+	names  map[int]string
+}
+
+/**
+ * 创建视频名称缓存
+ * @return *VideoNames
+ */
+func (this *LetvCloudV1) NewVideoNames() *VideoNames {
+	return &VideoNames{client: this, names: make(map[int]string)}
+}
+
+/**
+ * 通过video.list一次性加载所有视频名称，视频较多时比逐个调用video.get快
+ * @param  context.Context ctx 请求上下文
+ * @return error
+ */
+func (this *VideoNames) Preload(ctx context.Context) error {
+	it := this.client.ListVideos(ctx, VideoListRequest{})
+	for it.Next() {
+		v := it.Video()
+		this.Set(v.VideoId, v.VideoName)
+	}
+	return it.Err()
+}
+
+/**
+ * 手动设置视频名称
+ * @param  int video_id 视频ID
+ * @param  string name 视频名称
+ */
+func (this *VideoNames) Set(video_id int, name string) {
+	this.mu.Lock()
+	this.names[video_id] = name
+	this.mu.Unlock()
+}
+
+/**
+ * 获取视频名称，缓存中没有时调用video.get
+ * @param  context.Context ctx 请求上下文
+ * @param  int video_id 视频ID
+ * @return string, error 视频不存在（ErrVideoNotFound）时返回空字符串，其它错误原样返回且不缓存
+ */
+func (this *VideoNames) Name(ctx context.Context, video_id int) (string, error) {
+	this.mu.Lock()
+	name, ok := this.names[video_id]
+	this.mu.Unlock()
+	if ok {
+		return name, nil
+	}
+	video, err := this.client.VideoGet(ctx, video_id)
+	if err != nil {
+		if !errors.Is(err, ErrVideoNotFound) {
+			return "", err
+		}
+	} else {
+		name = video.VideoName
+	}
+	this.Set(video_id, name)
+	return name, nil
+}
+
+/**
+ * 导出配置，零值使用默认配置
+ */
+type ExportOptions struct {
+	// 补全视频名称，为nil时只使用接口返回的名称
+	Names *VideoNames
+	// CSV 开头写入 UTF-8 BOM，方便 Excel 打开
+	BOM bool
+}
+
+/**
+ * 统计数据导出，同一个导出只能写入一种数据，写完后调用Flush
+ */
+type StatExporter interface {
+	WriteHourStat(ctx context.Context, stat HourStat) error
+	WriteDateStat(ctx context.Context, stat DateStat) error
+	WriteTotalStat(ctx context.Context, stat TotalStat) error
+	Flush() error
+}
+
+// 统计数据种类，决定导出的列
+type statKind int
+
+const (
+	hourStatKind statKind = iota + 1
+	dateStatKind
+	totalStatKind
+)
+
+var statColumns = map[statKind][]string{
+	hourStatKind:  {"date", "hour", "video_id", "video_name", "play_count", "traffic"},
+	dateStatKind:  {"date", "video_id", "video_name", "play_count", "traffic"},
+	totalStatKind: {"date", "play_count", "traffic"},
+}
+
+// 导出用的统一数据行
+type statRecord struct {
+	Date      string  `json:"date"`
+	Hour      *int    `json:"hour,omitempty"`
+	VideoId   *int    `json:"video_id,omitempty"`
+	VideoName *string `json:"video_name,omitempty"`
+	PlayCount int64   `json:"play_count"`
+	Traffic   int64   `json:"traffic"`
+}
+
+func (this *statRecord) values(kind statKind) []string {
+	values := []string{this.Date}
+	if kind == hourStatKind {
+		values = append(values, strconv.Itoa(*this.Hour))
+	}
+	if kind != totalStatKind {
+		values = append(values, strconv.Itoa(*this.VideoId), *this.VideoName)
+	}
+	return append(values, strconv.FormatInt(this.PlayCount, 10), strconv.FormatInt(this.Traffic, 10))
+}
+
+// 两种导出格式共用的数据转换
+type statEncoder struct {
+	names *VideoNames
+	kind  statKind
+	write func(kind statKind, record *statRecord) error
+}
+
+func (this *statEncoder) encode(ctx context.Context, kind statKind, record *statRecord) error {
+	if this.kind == 0 {
+		this.kind = kind
+	} else if this.kind != kind {
+		return errors.New("letv: cannot mix stat kinds in one export")
+	}
+	if record.VideoId != nil && *record.VideoName == "" && this.names != nil {
+		name, err := this.names.Name(ctx, *record.VideoId)
+		if err != nil {
+			return err
+		}
+		record.VideoName = &name
+	}
+	return this.write(kind, record)
+}
+
+func (this *statEncoder) WriteHourStat(ctx context.Context, stat HourStat) error {
+	return this.encode(ctx, hourStatKind, &statRecord{
+		Date:      formatStatDate(stat.Date),
+		Hour:      &stat.Hour,
+		VideoId:   &stat.VideoId,
+		VideoName: &stat.VideoName,
+		PlayCount: stat.PlayCount,
+		Traffic:   stat.Traffic,
+	})
+}
+
+func (this *statEncoder) WriteDateStat(ctx context.Context, stat DateStat) error {
+	return this.encode(ctx, dateStatKind, &statRecord{
+		Date:      formatStatDate(stat.Date),
+		VideoId:   &stat.VideoId,
+		VideoName: &stat.VideoName,
+		PlayCount: stat.PlayCount,
+		Traffic:   stat.Traffic,
+	})
+}
+
+func (this *statEncoder) WriteTotalStat(ctx context.Context, stat TotalStat) error {
+	return this.encode(ctx, totalStatKind, &statRecord{
+		Date:      formatStatDate(stat.Date),
+		PlayCount: stat.PlayCount,
+		Traffic:   stat.Traffic,
+	})
+}
+
+/**
+ * CSV 导出，写入第一行数据前输出表头
+ */
+type CSVExporter struct {
+	statEncoder
+	w       io.Writer
+	csv     *csv.Writer
+	bom     bool
+	started bool
+}
+
+/**
+ * 创建 CSV 导出
+ * @param  io.Writer w
+ * @param  *ExportOptions opts 可以为nil
+ * @return *CSVExporter
+ */
+func NewCSVExporter(w io.Writer, opts *ExportOptions) *CSVExporter {
+	o := ExportOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	e := &CSVExporter{w: w, csv: csv.NewWriter(w), bom: o.BOM}
+	e.statEncoder = statEncoder{names: o.Names, write: e.writeRecord}
+	return e
+}
+
+func (this *CSVExporter) writeRecord(kind statKind, record *statRecord) error {
+	if !this.started {
+		this.started = true
+		if this.bom {
+			if _, err := io.WriteString(this.w, utf8BOM); err != nil {
+				return err
+			}
+		}
+		if err := this.csv.Write(statColumns[kind]); err != nil {
+			return err
+		}
+	}
+	return this.csv.Write(record.values(kind))
+}
+
+func (this *CSVExporter) Flush() error {
+	this.csv.Flush()
+	return this.csv.Error()
+}
+
+/**
+ * JSON Lines 导出，每行一个JSON对象
+ */
+type JSONLExporter struct {
+	statEncoder
+	enc *json.Encoder
+}
+
+/**
+ * 创建 JSON Lines 导出，忽略opts.BOM
+ * @param  io.Writer w
+ * @param  *ExportOptions opts 可以为nil
+ * @return *JSONLExporter
+ */
+func NewJSONLExporter(w io.Writer, opts *ExportOptions) *JSONLExporter {
+	o := ExportOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	e := &JSONLExporter{enc: json.NewEncoder(w)}
+	e.enc.SetEscapeHTML(false)
+	e.statEncoder = statEncoder{names: o.Names, write: e.writeRecord}
+	return e
+}
+
+func (this *JSONLExporter) writeRecord(kind statKind, record *statRecord) error {
+	return this.enc.Encode(record)
+}
+
+func (this *JSONLExporter) Flush() error {
+	return nil
+}
+
+/**
+ * 将遍历到的视频小时数据全部写入导出，不调用Flush
+ * @param  context.Context ctx 请求上下文
+ * @param  StatExporter e
+ * @param  *HourStatIterator it
+ * @return error
+ */
+func ExportHourStats(ctx context.Context, e StatExporter, it *HourStatIterator) error {
+	for it.Next() {
+		if err := e.WriteHourStat(ctx, it.Stat()); err != nil {
+			it.Stop()
+			return err
+		}
+	}
+	return it.Err()
+}
+
+/**
+ * 将视频天数据全部写入导出，不调用Flush
+ * @param  context.Context ctx 请求上下文
+ * @param  StatExporter e
+ * @param  []DateStat stats 可以使用FetchDateStats获取
+ * @return error
+ */
+func ExportDateStats(ctx context.Context, e StatExporter, stats []DateStat) error {
+	for _, stat := range stats {
+		if err := e.WriteDateStat(ctx, stat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+/**
+ * 将所有数据全部写入导出，不调用Flush
+ * @param  context.Context ctx 请求上下文
+ * @param  StatExporter e
+ * @param  []TotalStat stats 可以使用FetchTotalStats获取
+ * @return error
+ */
+func ExportTotalStats(ctx context.Context, e StatExporter, stats []TotalStat) error {
+	for _, stat := range stats {
+		if err := e.WriteTotalStat(ctx, stat); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,56 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"net/url"
+	"testing"
+)
+
+func TestVideoNamesName(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		wantName string
+		wantErr  bool
+		// 第二次查询是否使用缓存
+		wantCached bool
+	}{
+		{name: "found", body: `{"code":0,"data":{"video_id":7,"video_name":"片头"}}`, wantName: "片头", wantCached: true},
+		{name: "deleted video", body: `{"code":0,"data":[]}`, wantName: "", wantCached: true},
+		{name: "api error", body: `{"code":10002,"message":"bad sign"}`, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, c := newFakeApi(t, func(url.Values) string { return tt.body })
+			names := c.NewVideoNames()
+			for i := 0; i < 2; i++ {
+				name, err := names.Name(context.Background(), 7)
+				if (err != nil) != tt.wantErr {
+					t.Fatalf("call %d: err = %v, wantErr %v", i, err, tt.wantErr)
+				}
+				if name != tt.wantName {
+					t.Errorf("call %d: name = %q, want %q", i, name, tt.wantName)
+				}
+			}
+			want := 2
+			if tt.wantCached {
+				want = 1
+			}
+			if n := len(f.requests()); n != want {
+				t.Errorf("sent %d requests, want %d", n, want)
+			}
+		})
+	}
+}
+
+func TestExportFailsOnNameLookupError(t *testing.T) {
+	_, c := newFakeApi(t, func(url.Values) string { return `{"code":10006,"message":"denied"}` })
+	var buf bytes.Buffer
+	exporter := NewCSVExporter(&buf, &ExportOptions{Names: c.NewVideoNames()})
+	err := exporter.WriteDateStat(context.Background(), DateStat{Date: StatDate(2016, 1, 2), VideoId: 7})
+	if !errors.Is(err, &APIError{Code: 10006}) {
+		t.Errorf("err = %v, want APIError code 10006", err)
+	}
+}
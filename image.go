@@ -0,0 +1,190 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"mime"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+/**
+ * 截图尺寸，格式为 宽_高
+ */
+type ImageSize string
+
+const (
+	IMAGE_SIZE_100_100  ImageSize = "100_100"
+	IMAGE_SIZE_120_90   ImageSize = "120_90"
+	IMAGE_SIZE_160_120  ImageSize = "160_120"
+	IMAGE_SIZE_200_150  ImageSize = "200_150"
+	IMAGE_SIZE_320_180  ImageSize = "320_180"
+	IMAGE_SIZE_320_240  ImageSize = "320_240"
+	IMAGE_SIZE_640_360  ImageSize = "640_360"
+	IMAGE_SIZE_640_480  ImageSize = "640_480"
+	IMAGE_SIZE_1280_720 ImageSize = "1280_720"
+)
+
+// 每种尺寸的截图数
+const ScreenshotsPerSize = 8
+
+var imageSizePattern = regexp.MustCompile(`^[1-9][0-9]*_[1-9][0-9]*$`)
+
+/**
+ * 是否为 宽_高 格式
+ * @return bool
+ */
+func (this ImageSize) IsValid() bool {
+	return imageSizePattern.MatchString(string(this))
+}
+
+// 截图返回内容不是图片
+var ErrNotImage = errors.New("letv: not an image")
+
+/**
+ * 视频截图
+ */
+type Screenshot struct {
+	Size  ImageSize
+	Index int
+	Url   string
+}
+
+/**
+ * 转换为截图列表，按尺寸和序号排序，忽略空地址
+ * @return []Screenshot
+ */
+func (this Images) Screenshots() []Screenshot {
+	shots := make([]Screenshot, 0, len(this)*ScreenshotsPerSize)
+	for size, urls := range this {
+		for i, u := range urls {
+			if len(u) > 0 {
+				shots = append(shots, Screenshot{Size: ImageSize(size), Index: i, Url: u})
+			}
+		}
+	}
+	sort.Slice(shots, func(i, j int) bool {
+		if shots[i].Size != shots[j].Size {
+			return shots[i].Size < shots[j].Size
+		}
+		return shots[i].Index < shots[j].Index
+	})
+	return shots
+}
+
+/**
+ * 下载截图并写入w
+ * @param  context.Context ctx 请求上下文
+ * @param  Screenshot shot 截图
+ * @param  io.Writer w
+ * @return string, error 图片Content-Type, error
+ */
+func (this *LetvCloudV1) DownloadScreenshot(ctx context.Context, shot Screenshot, w io.Writer) (string, error) {
+	if this.getTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.getTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", shot.Url, nil)
+	if err != nil {
+		return "", err
+	}
+	if len(this.userAgent) > 0 {
+		req.Header.Set("User-Agent", this.userAgent)
+	}
+	resp, err := this.client.Do(req)
+	if err != nil {
+		return "", &TransportError{Op: "GET", Url: shot.Url, Err: err}
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return "", &TransportError{Op: "GET", Url: shot.Url, StatusCode: resp.StatusCode, Err: errors.New(resp.Status)}
+	}
+	contentType := resp.Header.Get("Content-Type")
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	if !strings.HasPrefix(mediaType, "image/") {
+		return "", fmt.Errorf("%w: %s content type %q", ErrNotImage, shot.Url, contentType)
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return "", &TransportError{Op: "GET", Url: shot.Url, StatusCode: resp.StatusCode, Err: err}
+	}
+	return mediaType, nil
+}
+
+/**
+ * 并发下载截图到目录，文件名为 尺寸_序号.扩展名
+ * @param  context.Context ctx 请求上下文
+ * @param  []Screenshot shots 截图
+ * @param  string dir 保存目录，不存在时自动创建
+ * @param  int concurrency 并发数，小于等于0时使用默认值
+ * @return []string, error 与shots对应的文件路径，下载失败的为空；存在失败时返回第一个错误
+ */
+func (this *LetvCloudV1) DownloadScreenshots(ctx context.Context, shots []Screenshot, dir string, concurrency int) ([]string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, err
+	}
+	paths := make([]string, len(shots))
+	errs := make([]error, len(shots))
+	runBulk(len(shots), concurrency, func(i int) {
+		paths[i], errs[i] = this.downloadScreenshotFile(ctx, shots[i], dir)
+	})
+	for _, err := range errs {
+		if err != nil {
+			return paths, err
+		}
+	}
+	return paths, nil
+}
+
+// 先下载到临时文件，确认是图片后按Content-Type确定扩展名
+func (this *LetvCloudV1) downloadScreenshotFile(ctx context.Context, shot Screenshot, dir string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	name := fmt.Sprintf("%s_%d", shot.Size, shot.Index)
+	fh, err := ioutil.TempFile(dir, name+".*.tmp")
+	if err != nil {
+		return "", err
+	}
+	tmp := fh.Name()
+	mediaType, err := this.DownloadScreenshot(ctx, shot, fh)
+	if cerr := fh.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	path := filepath.Join(dir, name+imageExtension(mediaType))
+	if err := os.Rename(tmp, path); err != nil {
+		os.Remove(tmp)
+		return "", err
+	}
+	return path, nil
+}
+
+// 图片类型对应的扩展名
+func imageExtension(mediaType string) string {
+	switch mediaType {
+	case "image/jpeg", "image/jpg", "image/pjpeg":
+		return ".jpg"
+	case "image/png":
+		return ".png"
+	case "image/gif":
+		return ".gif"
+	case "image/webp":
+		return ".webp"
+	}
+	if exts, _ := mime.ExtensionsByType(mediaType); len(exts) > 0 {
+		return exts[0]
+	}
+	return ".img"
+}
@@ -0,0 +1,251 @@
+package sdk
+
+import (
+	"context"
+)
+
+// 分页大小最大值
+const MaxPageSize = 100
+
+/**
+ * 分页遍历，按需请求下一页，最后一页或出错后停止
+ * fetch 请求第index页，返回本页条数和总条数
+ */
+type pageIterator struct {
+	ctx   context.Context
+	fetch func(ctx context.Context, index, size int) (int, int, error)
+	index int
+	size  int
+
+	total int
+	seen  int
+	pos   int
+	n     int
+	done  bool
+	err   error
+}
+
+func newPageIterator(ctx context.Context, index, size int, fetch func(ctx context.Context, index, size int) (int, int, error)) *pageIterator {
+	if index <= 0 {
+		index = 1
+	}
+	if size <= 0 || size > MaxPageSize {
+		size = MaxPageSize
+	}
+	return &pageIterator{ctx: ctx, fetch: fetch, index: index, size: size, pos: -1}
+}
+
+// 移动到下一条，当前页遍历完后请求下一页
+func (this *pageIterator) next() bool {
+	if this.err != nil {
+		return false
+	}
+	this.pos++
+	if this.pos < this.n {
+		return true
+	}
+	if this.done {
+		return false
+	}
+	if err := this.ctx.Err(); err != nil {
+		this.err = err
+		return false
+	}
+	n, total, err := this.fetch(this.ctx, this.index, this.size)
+	if err != nil {
+		this.err = err
+		return false
+	}
+	this.index++
+	this.total = total
+	this.seen += n
+	this.pos = 0
+	this.n = n
+	if n < this.size || (total > 0 && this.seen >= total) {
+		this.done = true
+	}
+	return n > 0
+}
+
+/**
+ * 遍历过程中的错误，Next返回false后调用
+ * @return error
+ */
+func (this *pageIterator) Err() error {
+	return this.err
+}
+
+/**
+ * 总条数，取自最近一次请求的返回，第一次调用Next之前为0
+ * @return int
+ */
+func (this *pageIterator) Total() int {
+	return this.total
+}
+
+/**
+ * 提前结束遍历，之后Next返回false
+ */
+func (this *pageIterator) Stop() {
+	this.done = true
+	this.pos = this.n
+}
+
+/**
+ * 视频列表遍历
+ *
+ *	it := client.ListVideos(ctx, sdk.VideoListRequest{})
+ *	for it.Next() {
+ *		video := it.Video()
+ *	}
+ *	if err := it.Err(); err != nil {
+ *	}
+ */
+type VideoIterator struct {
+	*pageIterator
+	page []Video
+}
+
+func (this *VideoIterator) Next() bool {
+	return this.next()
+}
+
+func (this *VideoIterator) Video() Video {
+	return this.page[this.pos]
+}
+
+/**
+ * 遍历视频列表的所有页
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoListRequest filter 查询条件，Index为开始页，Size为每页请求条数，默认为100
+ * @return *VideoIterator
+ */
+func (this *LetvCloudV1) ListVideos(ctx context.Context, filter VideoListRequest) *VideoIterator {
+	it := &VideoIterator{}
+	it.pageIterator = newPageIterator(ctx, filter.Index, filter.Size, func(ctx context.Context, index, size int) (int, int, error) {
+		req := filter
+		req.Index = index
+		req.Size = size
+		page, err := this.VideoList(ctx, req)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = page.Videos
+		return len(page.Videos), page.Total, nil
+	})
+	return it
+}
+
+/**
+ * 视频小时数据遍历
+ */
+type HourStatIterator struct {
+	*pageIterator
+	page []HourStat
+}
+
+func (this *HourStatIterator) Next() bool {
+	return this.next()
+}
+
+func (this *HourStatIterator) Stat() HourStat {
+	return this.page[this.pos]
+}
+
+/**
+ * 遍历视频小时数据的所有页
+ * @param  context.Context ctx 请求上下文
+ * @param  DataVideoHourRequest req 查询条件，Index为开始页，Size为每页请求条数，默认为100
+ * @return *HourStatIterator
+ */
+func (this *LetvCloudV1) ListHourStats(ctx context.Context, req DataVideoHourRequest) *HourStatIterator {
+	it := &HourStatIterator{}
+	it.pageIterator = newPageIterator(ctx, req.Index, req.Size, func(ctx context.Context, index, size int) (int, int, error) {
+		r := req
+		r.Index = index
+		r.Size = size
+		page, err := this.DataVideoHour(ctx, r)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = page.Stats
+		return len(page.Stats), page.Total, nil
+	})
+	return it
+}
+
+/**
+ * 视频天数据遍历
+ */
+type DateStatIterator struct {
+	*pageIterator
+	page []DateStat
+}
+
+func (this *DateStatIterator) Next() bool {
+	return this.next()
+}
+
+func (this *DateStatIterator) Stat() DateStat {
+	return this.page[this.pos]
+}
+
+/**
+ * 遍历视频天数据的所有页
+ * @param  context.Context ctx 请求上下文
+ * @param  DataVideoDateRequest req 查询条件，Index为开始页，Size为每页请求条数，默认为100
+ * @return *DateStatIterator
+ */
+func (this *LetvCloudV1) ListDateStats(ctx context.Context, req DataVideoDateRequest) *DateStatIterator {
+	it := &DateStatIterator{}
+	it.pageIterator = newPageIterator(ctx, req.Index, req.Size, func(ctx context.Context, index, size int) (int, int, error) {
+		r := req
+		r.Index = index
+		r.Size = size
+		page, err := this.DataVideoDate(ctx, r)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = page.Stats
+		return len(page.Stats), page.Total, nil
+	})
+	return it
+}
+
+/**
+ * 所有数据遍历
+ */
+type TotalStatIterator struct {
+	*pageIterator
+	page []TotalStat
+}
+
+func (this *TotalStatIterator) Next() bool {
+	return this.next()
+}
+
+func (this *TotalStatIterator) Stat() TotalStat {
+	return this.page[this.pos]
+}
+
+/**
+ * 遍历所有数据的所有页
+ * @param  context.Context ctx 请求上下文
+ * @param  DataTotalDateRequest req 查询条件，Index为开始页，Size为每页请求条数，默认为100
+ * @return *TotalStatIterator
+ */
+func (this *LetvCloudV1) ListTotalStats(ctx context.Context, req DataTotalDateRequest) *TotalStatIterator {
+	it := &TotalStatIterator{}
+	it.pageIterator = newPageIterator(ctx, req.Index, req.Size, func(ctx context.Context, index, size int) (int, int, error) {
+		r := req
+		r.Index = index
+		r.Size = size
+		page, err := this.DataTotalDate(ctx, r)
+		if err != nil {
+			return 0, 0, err
+		}
+		it.page = page.Stats
+		return len(page.Stats), page.Total, nil
+	})
+	return it
+}
@@ -0,0 +1,115 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"testing"
+)
+
+func TestPageIteratorStopConditions(t *testing.T) {
+	errPage := errors.New("page failed")
+	tests := []struct {
+		name string
+		// 每页返回的条数，-1表示返回errPage
+		pages       []int
+		total       int
+		stopAfter   int
+		wantItems   int
+		wantFetches int
+		wantErr     error
+	}{
+		{name: "last page short", pages: []int{100, 100, 50}, total: 250, wantItems: 250, wantFetches: 3},
+		{name: "exact multiple stops on total", pages: []int{100, 100}, total: 200, wantItems: 200, wantFetches: 2},
+		{name: "no total stops on empty page", pages: []int{100, 100, 0}, total: 0, wantItems: 200, wantFetches: 3},
+		{name: "short page before total", pages: []int{100, 30}, total: 500, wantItems: 130, wantFetches: 2},
+		{name: "empty first page", pages: []int{0}, total: 0, wantItems: 0, wantFetches: 1},
+		{name: "error on second page", pages: []int{100, -1}, total: 300, wantItems: 100, wantFetches: 2, wantErr: errPage},
+		{name: "stop early", pages: []int{100, 100}, total: 200, stopAfter: 5, wantItems: 5, wantFetches: 1},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			fetches := 0
+			it := newPageIterator(context.Background(), 0, 0, func(ctx context.Context, index, size int) (int, int, error) {
+				fetches++
+				if index != fetches {
+					t.Errorf("fetch index = %d, want %d", index, fetches)
+				}
+				if size != MaxPageSize {
+					t.Errorf("fetch size = %d, want %d", size, MaxPageSize)
+				}
+				if fetches > len(tt.pages) {
+					t.Fatalf("unexpected fetch %d", fetches)
+				}
+				n := tt.pages[fetches-1]
+				if n < 0 {
+					return 0, 0, errPage
+				}
+				return n, tt.total, nil
+			})
+
+			items := 0
+			for it.next() {
+				items++
+				if items == tt.stopAfter {
+					it.Stop()
+				}
+			}
+			if it.next() {
+				t.Errorf("next returned true after the end")
+			}
+			if items != tt.wantItems {
+				t.Errorf("items = %d, want %d", items, tt.wantItems)
+			}
+			if fetches != tt.wantFetches {
+				t.Errorf("fetches = %d, want %d", fetches, tt.wantFetches)
+			}
+			if it.Err() != tt.wantErr {
+				t.Errorf("err = %v, want %v", it.Err(), tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestPageIteratorCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	it := newPageIterator(ctx, 1, 10, func(ctx context.Context, index, size int) (int, int, error) {
+		t.Fatal("fetch called after cancel")
+		return 0, 0, nil
+	})
+	if it.next() || it.Err() != context.Canceled {
+		t.Errorf("err = %v, want context.Canceled", it.Err())
+	}
+}
+
+func TestListVideosWalksPages(t *testing.T) {
+	f, c := newFakeApi(t, func(q url.Values) string {
+		if q.Get("index") == "1" {
+			return `{"code":0,"total":3,"data":[{"video_id":1,"status":10},{"video_id":2,"status":30}]}`
+		}
+		return `{"code":0,"total":3,"data":[{"video_id":3,"status":20}]}`
+	})
+	it := c.ListVideos(context.Background(), VideoListRequest{Size: 2, Status: Status(ALL)})
+	ids := ""
+	for it.Next() {
+		ids += strconv.Itoa(it.Video().VideoId)
+	}
+	if it.Err() != nil {
+		t.Fatal(it.Err())
+	}
+	if ids != "123" || it.Total() != 3 {
+		t.Errorf("ids = %q, total = %d", ids, it.Total())
+	}
+	reqs := f.requests()
+	if len(reqs) != 2 {
+		t.Fatalf("got %d requests, want 2", len(reqs))
+	}
+	for i, q := range reqs {
+		if q.Get("api") != "video.list" || q.Get("index") != strconv.Itoa(i+1) || q.Get("size") != "2" || q.Get("status") != "0" {
+			t.Errorf("request %d = %v", i, q)
+		}
+	}
+}
@@ -1,18 +1,19 @@
 package sdk
 
 import (
-	"bytes"
+	"context"
 	"crypto/md5"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
-	"mime/multipart"
 	"net"
 	"net/http"
 	"net/url"
 	"os"
+	"path/filepath"
 	"sort"
 	"strconv"
 	"strings"
@@ -21,18 +22,10 @@ import (
 
 //乐视云SDK
 
+//上传方式
 const (
-	ALL     int = 0
-	PLAY_OK int = 10
-	FAILED  int = 20
-	WAIT    int = 30
-)
-
-var (
-	//	post timeout时间
-	PTimeOut time.Duration
-	//	get timeout时间
-	GTimeOut time.Duration
+	UPLOAD_NORMAL  int = 0
+	UPLOAD_CHUNKED int = 1
 )
 
 type LetvCloudV1 struct {
@@ -41,20 +34,41 @@ type LetvCloudV1 struct {
 	restUrl    string
 	format     string
 	apiVersion string
+
+	client      *http.Client
+	proxy       *url.URL
+	userAgent   string
+	getTimeout  time.Duration
+	postTimeout time.Duration
+
+	clientIpResolver ClientIpResolver
 }
 
-func NewLetvCloudV1(unique, key string) *LetvCloudV1 {
-	return &LetvCloudV1{userUnique: unique, secretKey: key, restUrl: "http://api.letvcloud.com/open.php", format: "json", apiVersion: "2.0"}
+func NewLetvCloudV1(unique, key string, opts ...Option) *LetvCloudV1 {
+	this := &LetvCloudV1{userUnique: unique, secretKey: key, restUrl: "http://api.letvcloud.com/open.php", format: "json", apiVersion: "2.0",
+		getTimeout: DefaultGetTimeout, postTimeout: DefaultPostTimeout}
+	for _, opt := range opts {
+		opt(this)
+	}
+	if this.client == nil {
+		this.client = newHTTPClient(this.proxy)
+	}
+	return this
 }
 
 /**
  * 视频上传初始化
+ * @param  context.Context ctx 请求上下文
  * @param  string video_name 视频名称
- * @param  string client_ip  用户IP地址
- * @return []byte
+ * @param  *UploadInitOptions opts 可选参数，可以为nil
+ * @return *UploadInitResult, error
  */
-func (this *LetvCloudV1) VideoUploadInit(video_name string) []byte {
-	return this.videoUploadInit_(video_name, "", 0)
+func (this *LetvCloudV1) VideoUploadInit(ctx context.Context, video_name string, opts *UploadInitOptions) (*UploadInitResult, error) {
+	o := UploadInitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	return this.videoUploadInit_(ctx, video_name, o)
 }
 
 func (this *LetvCloudV1) SetSecretKey(secretKey string) {
@@ -72,618 +86,523 @@ func (this *LetvCloudV1) SetApiVersion(apiVersion string) {
 
 /**
  * 视频上传初始化
+ * @param  context.Context ctx 请求上下文
  * @param  string video_name 视频名称
- * @param  string client_ip  用户IP地址
- * @param  int file_size  文件大小，单位为字节
- * @return []byte
+ * @param  UploadInitOptions opts 可选参数，ClientIp为空时使用WithClientIpResolver设置的方法获取
+ * @return *UploadInitResult, error
  */
-func (this *LetvCloudV1) videoUploadInit_(video_name string, client_ip string, file_size int) []byte {
+func (this *LetvCloudV1) videoUploadInit_(ctx context.Context, video_name string, opts UploadInitOptions) (*UploadInitResult, error) {
 	api := "video.upload.init"
 	params := make(map[interface{}]interface{})
 
+	if err := validateVideoName(video_name); err != nil {
+		return nil, err
+	}
 	params["video_name"] = video_name
+
+	client_ip := opts.ClientIp
+	if len(client_ip) == 0 && this.clientIpResolver != nil {
+		ip, err := this.clientIpResolver(ctx)
+		if err != nil {
+			return nil, err
+		}
+		client_ip = ip
+	}
 	if len(client_ip) > 0 {
+		if ip := net.ParseIP(client_ip); ip == nil || ip.To4() == nil {
+			return nil, &ValidationError{Field: "client_ip", Reason: "not an IPv4 address"}
+		}
 		params["client_ip"] = client_ip
 	}
-	if file_size > 0 {
-
-		params["file_size"] = strconv.Itoa(file_size)
+	if opts.FileSize < 0 {
+		return nil, &ValidationError{Field: "file_size", Reason: "must not be negative"}
+	}
+	if opts.FileSize > 0 {
+		params["file_size"] = strconv.FormatInt(opts.FileSize, 10)
+	}
+	if opts.UploadType != UPLOAD_NORMAL && opts.UploadType != UPLOAD_CHUNKED {
+		return nil, &ValidationError{Field: "uploadtype", Reason: "must be UPLOAD_NORMAL or UPLOAD_CHUNKED"}
+	}
+	if opts.UploadType == UPLOAD_CHUNKED {
+		params["uploadtype"] = strconv.Itoa(opts.UploadType)
+	}
+	if opts.IsDownload {
+		params["isdownload"] = "1"
 	}
-	return this.makeRequest(api, params)
+	if opts.IsDrm {
+		params["isdrm"] = "1"
+	}
+	if opts.IsPay {
+		params["ispay"] = "1"
+	}
+	result := &UploadInitResult{}
+	if _, err := this.callApi(ctx, api, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 /**
  * 视频上传 (web方式)
+ * @param  context.Context ctx 请求上下文
+ * @param  string video_file 文件绝对路径
+ * @param  string upload_url 视频上传地址，视频上传时提交地址
+ * @return error
+ */
+func (this *LetvCloudV1) VideoUpload(ctx context.Context, video_file, upload_url string) error {
+	return this.VideoUploadWithProgress(ctx, video_file, upload_url, nil)
+}
+
+/**
+ * 视频上传 (web方式)，上传过程中回调进度
+ * @param  context.Context ctx 请求上下文
  * @param  string video_file 文件绝对路径
  * @param  string upload_url 视频上传地址，视频上传时提交地址
- * @return []byte
+ * @param  TransferFunc progress 进度回调，可以为nil
+ * @return error
  */
-func (this *LetvCloudV1) VideoUpload(video_file, upload_url string) []byte {
-	return this.doUploadFile(video_file, upload_url)
+func (this *LetvCloudV1) VideoUploadWithProgress(ctx context.Context, video_file, upload_url string, progress TransferFunc) error {
+	body, err := this.doUploadFile(ctx, video_file, upload_url, progress)
+	if err != nil {
+		return err
+	}
+	_, err = decodeResponse("video.upload", body, nil)
+	return err
 }
 
 /**
  * 视频上传（Flash方式）
- * @param  string video_name 视频名称
- * @param  string js_callback Javascript回调函数，视频上传完毕后调用
- * @param  int flash_width Flash宽度，默认值为600
- * @param  int flash_height Flash高度，默认值为450
- * @param  string client_ip 用户IP地址
- * @return  []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoUploadFlashRequest req 请求参数
+ * @return *FlashUploadResult, error
  */
-func (this *LetvCloudV1) videoUploadFlash(video_name, js_callback string, flash_width, flash_height int, client_ip string) []byte {
+func (this *LetvCloudV1) VideoUploadFlash(ctx context.Context, req VideoUploadFlashRequest) (*FlashUploadResult, error) {
 	api := "video.upload.flash"
 	params := make(map[interface{}]interface{})
-	params["video_name"] = video_name
-	if len(js_callback) > 0 {
-		params["js_callback"] = js_callback
+	if err := validateVideoName(req.VideoName); err != nil {
+		return nil, err
 	}
-	if flash_width > 0 {
-		params["flash_width"] = strconv.Itoa(flash_width)
+	params["video_name"] = req.VideoName
+	if len(req.JsCallback) > 0 {
+		params["js_callback"] = req.JsCallback
 	}
-	if flash_height > 0 {
-		params["flash_height"] = strconv.Itoa(flash_height)
+	if req.FlashWidth > 0 {
+		params["flash_width"] = strconv.Itoa(req.FlashWidth)
 	}
-	if len(client_ip) > 0 {
-		params["client_ip"] = client_ip
+	if req.FlashHeight > 0 {
+		params["flash_height"] = strconv.Itoa(req.FlashHeight)
 	}
-	return this.makeRequest(api, params)
-}
-
-/**
- * 视频上传（Flash方式）
- * @param  string video_name 视频名称
- * @param  string js_callback Javascript回调函数，视频上传完毕后调用
- * @param  int flash_width Flash宽度，默认值为600
- * @param  int flash_height Flash高度，默认值为450
- * @return []byte
- */
-func (this *LetvCloudV1) videoUploadFlash_(video_name, js_callback string, flash_width, flash_height int) []byte {
-	return this.videoUploadFlash(video_name, js_callback, flash_width, flash_height, "")
-}
-
-/**
- * 视频上传（Flash方式）
- * @param  string video_name 视频名称
- * @param  string js_callback Javascript回调函数，视频上传完毕后调用
- * @param  int flash_width Flash宽度，默认值为600
- * @return []byte
- */
-func (this *LetvCloudV1) videoUploadFlash_1(video_name, js_callback string, flash_width int) []byte {
-	return this.videoUploadFlash(video_name, js_callback, flash_width, 0, "")
-}
-
-/**
- * 视频上传（Flash方式）
- * @param  string video_name 视频名称
- * @param  string js_callback Javascript回调函数，视频上传完毕后调用
- * @return []byte
- */
-func (this *LetvCloudV1) videoUploadFlash_2(video_name, js_callback string) []byte {
-	return this.videoUploadFlash(video_name, js_callback, 0, 0, "")
+	if len(req.ClientIp) > 0 {
+		params["client_ip"] = req.ClientIp
+	}
+	result := &FlashUploadResult{}
+	if _, err := this.callApi(ctx, api, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 /**
- * 视频上传（Flash方式）
- * @param  string video_name 视频名称
- * @return []byte
+ * 视频断点续传
+ * @param  context.Context ctx 请求上下文
+ * @param  string token 视频上传标识
+ * @return *UploadResumeResult, error
  */
-func (this *LetvCloudV1) videoUploadFlash_3(video_name string) []byte {
-	return this.videoUploadFlash(video_name, "", 0, 0, "")
+func (this *LetvCloudV1) VideoUploadResume(ctx context.Context, token string) (*UploadResumeResult, error) {
+	return this.videoUploadResume_(ctx, token, -1)
 }
 
 /**
  * 视频断点续传
+ * @param  context.Context ctx 请求上下文
  * @param  string token 视频上传标识
- * @return String
+ * @param  int uploadtype 是否分片上传：UPLOAD_NORMAL不分片；UPLOAD_CHUNKED分片；-1表示不传
+ * @return *UploadResumeResult, error
  */
-func (this *LetvCloudV1) VideoUploadResume(token string) string {
+func (this *LetvCloudV1) videoUploadResume_(ctx context.Context, token string, uploadtype int) (*UploadResumeResult, error) {
 	api := "video.upload.resume"
 	params := make(map[interface{}]interface{})
 	params["token"] = token
-	return string(this.makeRequest(api, params))
+	if uploadtype == UPLOAD_NORMAL || uploadtype == UPLOAD_CHUNKED {
+		params["uploadtype"] = strconv.Itoa(uploadtype)
+	}
+	result := &UploadResumeResult{}
+	if _, err := this.callApi(ctx, api, params, result); err != nil {
+		return nil, err
+	}
+	return result, nil
 }
 
 /**
  * 构造云视频Sign
  * @param params 业务参数
- * @return string
+ * @return string, error
  */
-func (this *LetvCloudV1) generateSign(params map[interface{}]interface{}) string {
+func (this *LetvCloudV1) generateSign(params map[interface{}]interface{}) (string, error) {
 	array := make([]string, 0)
 
 	for key := range params {
-		array = append(array, key.(string))
+		k, ok := key.(string)
+		if !ok {
+			return "", &SignError{Key: fmt.Sprint(key)}
+		}
+		array = append(array, k)
 	}
 	sort.Strings(array)
 	keyStr := ""
 	for _, v := range array {
-
-		keyStr = keyStr + v + params[v].(string)
+		value, ok := params[v].(string)
+		if !ok {
+			return "", &SignError{Key: v}
+		}
+		keyStr = keyStr + v + value
 	}
 	keyStr += this.secretKey
 	//	fmt.Println("md5:", keyStr)
-	return this.md5_(keyStr)
+	return this.md5_(keyStr), nil
 }
 
 /**
  * 视频信息更新
- * @param  int video_id 视频ID
- * @param  string video_name 视频名称
- * @param  string video_desc 视频简介
- * @param  string tag 标签
- * @param  int is_pay 视频是否收费：0表示不收费；1表示收费（收费视频播放时会进行用户鉴权，请不要随便设置）
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoUpdateRequest req 请求参数
+ * @return error
  */
-func (this *LetvCloudV1) videoUpdate(video_id int, video_name, video_desc, tag string, is_pay int) []byte {
-	api := "video.upload.init"
+func (this *LetvCloudV1) VideoUpdate(ctx context.Context, req VideoUpdateRequest) error {
+	api := "video.update"
 	params := make(map[interface{}]interface{})
 
-	params["video_id"] = strconv.Itoa(video_id)
+	if req.VideoId <= 0 {
+		return &ValidationError{Field: "video_id", Reason: "must be positive"}
+	}
+	params["video_id"] = strconv.Itoa(req.VideoId)
 
-	if len(video_name) > 0 {
-		params["video_name"] = video_name
+	if req.VideoName != nil {
+		if err := validateVideoName(*req.VideoName); err != nil {
+			return err
+		}
+		params["video_name"] = *req.VideoName
 	}
-	if len(video_desc) > 0 {
-		params["video_desc"] = video_desc
+	if req.VideoDesc != nil {
+		params["video_desc"] = *req.VideoDesc
 	}
-	if len(tag) > 0 {
-		params["tag"] = tag
+	if req.Tag != nil {
+		params["tag"] = FormatTags(*req.Tag)
 	}
-
-	if is_pay == 0 || is_pay == 1 {
-		params["is_pay"] = strconv.Itoa(is_pay)
+	if req.IsPay != nil {
+		if *req.IsPay != 0 && *req.IsPay != 1 {
+			return &ValidationError{Field: "is_pay", Reason: "must be 0 or 1"}
+		}
+		params["is_pay"] = strconv.Itoa(*req.IsPay)
+	}
+	if len(params) == 1 {
+		return &ValidationError{Field: "video_id", Reason: "nothing to update"}
 	}
 
-	return this.makeRequest(api, params)
-}
-
-/**
- * 视频信息更新
- * @param  int video_id 视频ID
- * @param  string video_name 视频名称
- * @param  string video_desc 视频简介
- * @return []byte
- */
-func (this *LetvCloudV1) videoUpdate_1(video_id int, video_name, video_desc string) []byte {
-	return this.videoUpdate(video_id, video_name, video_desc, "", -1)
-}
-
-/**
- * 视频信息更新
- * @param  int video_id 视频ID
- * @return []byte
- */
-func (this *LetvCloudV1) videoUpdate_2(video_id int) []byte {
-	return this.videoUpdate(video_id, "", "", "", -1)
-}
-
-/**
- * 视频信息更新
- * @param  int video_id 视频ID
- * @param  string video_name 视频名称
- * @param  string video_desc 视频简介
- * @param  string tag 标签
- * @return []byte
- */
-func (this *LetvCloudV1) videoUpdate_(video_id int, video_name, video_desc, tag string) []byte {
-	return this.videoUpdate(video_id, video_name, video_desc, tag, -1)
+	_, err := this.callApi(ctx, api, params, nil)
+	return err
 }
 
 /**
  * 获取视频列表
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @param  const status 视频状态：ALL表示全部；PLAY_OK表示可以正常播放；FAILED表示处理失败；WAIT表示正在处理过程中。默认值为ALL
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  VideoListRequest req 请求参数
+ * @return *VideoPage, error
  */
-func (this *LetvCloudV1) videoList(index, size, status int) []byte {
+func (this *LetvCloudV1) VideoList(ctx context.Context, req VideoListRequest) (*VideoPage, error) {
 	api := "video.list"
 	params := make(map[interface{}]interface{})
-	if index > 0 {
-		params["index"] = strconv.Itoa(index)
+	if err := validatePage(req.Index, req.Size); err != nil {
+		return nil, err
 	}
-	if size > 0 {
-		params["size"] = strconv.Itoa(size)
+	if req.Index > 0 {
+		params["index"] = strconv.Itoa(req.Index)
 	}
-	if status == ALL || status == PLAY_OK || status == FAILED || status == WAIT {
-		params["status"] = strconv.Itoa(status)
+	if req.Size > 0 {
+		params["size"] = strconv.Itoa(req.Size)
 	}
-	return this.makeRequest(api, params)
-}
-
-/**
- * 获取视频列表
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
- */
-func (this *LetvCloudV1) videoList_(index, size int) []byte {
-	return this.videoList(index, size, -1)
-}
-
-/**
- * 获取视频列表
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
- */
-func (this *LetvCloudV1) videoList_1(index int) []byte {
-	return this.videoList(index, 0, -1)
-}
-
-/**
- * 获取视频列表
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
- */
-func (this *LetvCloudV1) videoList_2() []byte {
-	return this.videoList(0, 0, -1)
+	if req.Status != nil {
+		if !req.Status.IsValid() {
+			return nil, &ValidationError{Field: "status", Reason: "unknown video status " + req.Status.String()}
+		}
+		params["status"] = strconv.Itoa(int(*req.Status))
+	}
+	result := &VideoPage{}
+	resp, err := this.callApi(ctx, api, params, &result.Videos)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = resp.Total
+	return result, nil
 }
 
 /**
  * 获取单个视频信息
- * @param videoid 视频id
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  int video_id 视频ID
+ * @return *Video, error 接口未返回视频信息时返回ErrVideoNotFound
  */
-func (this *LetvCloudV1) videoGet(videoid int) []byte {
+func (this *LetvCloudV1) VideoGet(ctx context.Context, video_id int) (*Video, error) {
 	api := "video.get"
 	params := make(map[interface{}]interface{})
-	params["video_id"] = strconv.Itoa(videoid)
-	return this.makeRequest(api, params)
+	params["video_id"] = strconv.Itoa(video_id)
+	result := &Video{}
+	resp, err := this.callApi(ctx, api, params, result)
+	if err != nil {
+		return nil, err
+	}
+	if isEmptyData(resp.Data) {
+		return nil, ErrVideoNotFound
+	}
+	return result, nil
 }
 
 /**
  * 删除视频
+ * @param  context.Context ctx 请求上下文
  * @param  int video_id 视频ID
- * @return []byte
+ * @return error
  */
-func (this *LetvCloudV1) videoDel(video_id int) []byte {
+func (this *LetvCloudV1) VideoDel(ctx context.Context, video_id int) error {
 	api := "video.del"
 	params := make(map[interface{}]interface{})
 	params["video_id"] = strconv.Itoa(video_id)
-	return this.makeRequest(api, params)
+	_, err := this.callApi(ctx, api, params, nil)
+	return err
 }
 
 /**
  * 批量删除视频
- * @param  string video_id_list 视频ID列表，使用符号-作为间隔符，每次最多操作50条记录
- * @return String
+ * @param  context.Context ctx 请求上下文
+ * @param  []int video_ids 视频ID列表，每次最多操作50条记录
+ * @return error
  */
-func (this *LetvCloudV1) videoDelBatch(video_id_list string) []byte {
+func (this *LetvCloudV1) VideoDelBatch(ctx context.Context, video_ids []int) error {
 	api := "video.del.batch"
 	params := make(map[interface{}]interface{})
-	params["video_id_list"] = video_id_list
-	return this.makeRequest(api, params)
+	if len(video_ids) == 0 || len(video_ids) > MaxDelBatchSize {
+		return &ValidationError{Field: "video_id_list", Reason: "must contain 1 to " + strconv.Itoa(MaxDelBatchSize) + " ids"}
+	}
+	list := make([]string, len(video_ids))
+	for i, id := range video_ids {
+		list[i] = strconv.Itoa(id)
+	}
+	//使用符号-作为间隔符
+	params["video_id_list"] = strings.Join(list, "-")
+	_, err := this.callApi(ctx, api, params, nil)
+	return err
 }
 
 /**
  * 视频暂停
+ * @param  context.Context ctx 请求上下文
  * @param  int video_id 视频ID
- * @return String
+ * @return error
  */
-func (this *LetvCloudV1) videoPause(video_id int) []byte {
+func (this *LetvCloudV1) VideoPause(ctx context.Context, video_id int) error {
 	api := "video.pause"
 	params := make(map[interface{}]interface{})
 	params["video_id"] = strconv.Itoa(video_id)
-	return this.makeRequest(api, params)
+	_, err := this.callApi(ctx, api, params, nil)
+	return err
 }
 
 /**
  * 视频恢复
+ * @param  context.Context ctx 请求上下文
  * @param  int video_id 视频ID
- * @return String
+ * @return error
  */
-func (this *LetvCloudV1) videoRestore(video_id int) []byte {
+func (this *LetvCloudV1) VideoRestore(ctx context.Context, video_id int) error {
 	api := "video.restore"
 	params := make(map[interface{}]interface{})
 	params["video_id"] = strconv.Itoa(video_id)
-	return this.makeRequest(api, params)
+	_, err := this.callApi(ctx, api, params, nil)
+	return err
 }
 
 /**
  * 获取视频截图
+ * @param  context.Context ctx 请求上下文
  * @param  int video_id 视频ID
- * @param  string size 截图尺寸，每种尺寸各有8张图。
- * @return []byte
+ * @param  ImageSize size 截图尺寸，每种尺寸各有8张图。
+ * @return []Screenshot, error
  */
-func (this *LetvCloudV1) imageGet(video_id int, size string) []byte {
+func (this *LetvCloudV1) ImageGet(ctx context.Context, video_id int, size ImageSize) ([]Screenshot, error) {
 	api := "image.get"
 	params := make(map[interface{}]interface{})
 	params["video_id"] = strconv.Itoa(video_id)
-	params["size"] = size
-	return this.makeRequest(api, params)
+	if !size.IsValid() {
+		return nil, &ValidationError{Field: "size", Reason: "must be like 100_100"}
+	}
+	params["size"] = string(size)
+	result := Images{}
+	if _, err := this.callApi(ctx, api, params, &result); err != nil {
+		return nil, err
+	}
+	return result.Screenshots(), nil
 }
 
 /**
  * 视频小时数据
- * @param  string date 日期，格式为：yyyy-mm-dd
- * @param  int hour 小时，0-23之间
- * @param  int video_id 视频ID
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  DataVideoHourRequest req 请求参数
+ * @return *HourStatPage, error
  */
-func (this *LetvCloudV1) dataVideoHour(date string, hour, video_id, index, size int) []byte {
+func (this *LetvCloudV1) DataVideoHour(ctx context.Context, req DataVideoHourRequest) (*HourStatPage, error) {
 	api := "data.video.hour"
 	params := make(map[interface{}]interface{})
-	params["date"] = date
-	if hour >= 0 && hour <= 23 {
-		params["hour"] = strconv.Itoa(hour)
+	if err := validateStatDate("date", req.Date); err != nil {
+		return nil, err
 	}
-	if video_id > 0 {
-		params["video_id"] = strconv.Itoa(video_id)
+	params["date"] = formatStatDate(req.Date)
+	if req.Hour != nil {
+		if *req.Hour < 0 || *req.Hour > 23 {
+			return nil, &ValidationError{Field: "hour", Reason: "must be 0 to 23"}
+		}
+		params["hour"] = strconv.Itoa(*req.Hour)
 	}
-	if index > 0 {
-		params["index"] = strconv.Itoa(index)
+	if req.VideoId < 0 {
+		return nil, &ValidationError{Field: "video_id", Reason: "must not be negative"}
 	}
-	if size > 0 {
-		params["size"] = strconv.Itoa(size)
+	if req.VideoId > 0 {
+		params["video_id"] = strconv.Itoa(req.VideoId)
 	}
-	return this.makeRequest(api, params)
-}
-
-/**
- * 视频小时数据
- * @param  string date 日期，格式为：yyyy-mm-dd
- * @param  int hour 小时，0-23之间
- * @param  int video_id 视频ID
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoHour_(date string, hour, video_id, index int) []byte {
-	return this.dataVideoHour(date, hour, video_id, index, 0)
-}
-
-/**
- * 视频小时数据
- * @param  string date 日期，格式为：yyyy-mm-dd
- * @param  int hour 小时，0-23之间
- * @param  int video_id 视频ID
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoHour_1(date string, hour, video_id int) []byte {
-	return this.dataVideoHour(date, hour, video_id, 0, 0)
-}
-
-/**
- * 视频小时数据
- * @param  string date 日期，格式为：yyyy-mm-dd
- * @param  int hour 小时，0-23之间
- * @param  int video_id 视频ID
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoHour_2(date string, hour int) []byte {
-	return this.dataVideoHour(date, hour, 0, 0, 0)
-}
-
-/**
- * 视频小时数据
- * @param  string date 日期，格式为：yyyy-mm-dd
- * @param  int hour 小时，0-23之间
- * @param  int video_id 视频ID
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoHour_3(date string) []byte {
-	return this.dataVideoHour(date, -1, 0, 0, 0)
+	if err := validatePage(req.Index, req.Size); err != nil {
+		return nil, err
+	}
+	if req.Index > 0 {
+		params["index"] = strconv.Itoa(req.Index)
+	}
+	if req.Size > 0 {
+		params["size"] = strconv.Itoa(req.Size)
+	}
+	result := &HourStatPage{}
+	resp, err := this.callApi(ctx, api, params, &result.Stats)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = resp.Total
+	return result, nil
 }
 
 /**
  * 视频天数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int video_id 视频ID，不输入该参数将返回所有视频的数据
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  DataVideoDateRequest req 请求参数
+ * @return *DateStatPage, error
  */
-func (this *LetvCloudV1) dataVideoDate(start_date, end_date string, video_id, index, size int) []byte {
+func (this *LetvCloudV1) DataVideoDate(ctx context.Context, req DataVideoDateRequest) (*DateStatPage, error) {
 	api := "data.video.date"
 	params := make(map[interface{}]interface{})
-	params["start_date"] = start_date
-	params["end_date"] = end_date
-	if video_id > 0 {
-		params["video_id"] = strconv.Itoa(video_id)
+	if err := validateStatRange(req.StartDate, req.EndDate); err != nil {
+		return nil, err
 	}
-	if index > 0 {
-		params["index"] = strconv.Itoa(index)
+	params["start_date"] = formatStatDate(req.StartDate)
+	params["end_date"] = formatStatDate(req.EndDate)
+	if req.VideoId < 0 {
+		return nil, &ValidationError{Field: "video_id", Reason: "must not be negative"}
 	}
-	if size > 0 {
-		params["size"] = strconv.Itoa(size)
+	if req.VideoId > 0 {
+		params["video_id"] = strconv.Itoa(req.VideoId)
 	}
-	return this.makeRequest(api, params)
-}
-
-/**
- * 视频天数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int video_id 视频ID，不输入该参数将返回所有视频的数据
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoDate_(start_date, end_date string, video_id, index int) []byte {
-	return this.dataVideoDate(start_date, end_date, video_id, index, 0)
-}
-
-/**
- * 视频天数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int video_id 视频ID，不输入该参数将返回所有视频的数据
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoDate_1(start_date, end_date string, video_id int) []byte {
-	return this.dataVideoDate(start_date, end_date, video_id, 0, 0)
-}
-
-/**
- * 视频天数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int video_id 视频ID，不输入该参数将返回所有视频的数据
- * @param  int index 开始页索引，默认值为1
- * @return []byte
- */
-func (this *LetvCloudV1) dataVideoDate_2(start_date, end_date string) []byte {
-	return this.dataVideoDate(start_date, end_date, 0, 0, 0)
+	if err := validatePage(req.Index, req.Size); err != nil {
+		return nil, err
+	}
+	if req.Index > 0 {
+		params["index"] = strconv.Itoa(req.Index)
+	}
+	if req.Size > 0 {
+		params["size"] = strconv.Itoa(req.Size)
+	}
+	result := &DateStatPage{}
+	resp, err := this.callApi(ctx, api, params, &result.Stats)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = resp.Total
+	return result, nil
 }
 
 /**
  * 所有数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
+ * @param  context.Context ctx 请求上下文
+ * @param  DataTotalDateRequest req 请求参数
+ * @return *TotalStatPage, error
  */
-func (this *LetvCloudV1) dataTotalDate(start_date, end_date string, index, size int) []byte {
+func (this *LetvCloudV1) DataTotalDate(ctx context.Context, req DataTotalDateRequest) (*TotalStatPage, error) {
 	api := "data.total.date"
 	params := make(map[interface{}]interface{})
-	params["start_date"] = start_date
-	params["end_date"] = end_date
-	if index > 0 {
-		params["index"] = strconv.Itoa(index)
+	if err := validateStatRange(req.StartDate, req.EndDate); err != nil {
+		return nil, err
 	}
-	if size > 0 {
-		params["size"] = strconv.Itoa(size)
+	params["start_date"] = formatStatDate(req.StartDate)
+	params["end_date"] = formatStatDate(req.EndDate)
+	if err := validatePage(req.Index, req.Size); err != nil {
+		return nil, err
 	}
-	return this.makeRequest(api, params)
-}
-
-/**
- * 所有数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
- */
-func (this *LetvCloudV1) dataTotalDate_(start_date, end_date string, index int) []byte {
-	return this.dataTotalDate(start_date, end_date, index, 0)
-}
-
-/**
- * 所有数据
- * @param  string start_date 开始日期，格式为：yyyy-mm-dd
- * @param  string end_date 结束日期，格式为：yyyy-mm-dd
- * @param  int index 开始页索引，默认值为1
- * @param  int size 分页大小，默认值为10，最大值为100
- * @return []byte
- */
-func (this *LetvCloudV1) dataTotalDate_1(start_date, end_date string) []byte {
-	return this.dataTotalDate(start_date, end_date, 0, 0)
+	if req.Index > 0 {
+		params["index"] = strconv.Itoa(req.Index)
+	}
+	if req.Size > 0 {
+		params["size"] = strconv.Itoa(req.Size)
+	}
+	result := &TotalStatPage{}
+	resp, err := this.callApi(ctx, api, params, &result.Stats)
+	if err != nil {
+		return nil, err
+	}
+	result.Total = resp.Total
+	return result, nil
 }
 
 /**
  * 获取视频播放接口
- * @param string uu 用户唯一标识码，由乐视网统一分配并提供
- * @param string vu 视频唯一标识码
- * @param string type 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
- * @param string pu 播放器唯一标识码
- * @param int auto_play 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
- * @param int width 播放器宽度
- * @param int height 播放器高度
- * @return String
+ * @param  VideoGetPlayinterfaceRequest req 请求参数
+ * @return string, error
  */
-func (this *LetvCloudV1) videoGetPlayinterface(uu, vu, types, pu string, auto_play, width, height int) string {
+func (this *LetvCloudV1) VideoGetPlayinterface(req VideoGetPlayinterfaceRequest) (string, error) {
 	params := make(map[interface{}]interface{})
-	params["uu"] = uu
-	params["vu"] = vu
-	if len(pu) > 0 {
-		params["pu"] = pu
+	params["uu"] = req.Uu
+	params["vu"] = req.Vu
+	if len(req.Pu) > 0 {
+		params["pu"] = req.Pu
 	}
-	if auto_play != -1 {
-		params["auto_play"] = strconv.Itoa(auto_play)
+	if req.AutoPlay != nil {
+		params["auto_play"] = strconv.Itoa(*req.AutoPlay)
 	}
+	width := req.Width
 	if width > 0 {
 		params["width"] = strconv.Itoa(width)
 	} else {
 		width = 800
 	}
+	height := req.Height
 	if height > 0 {
 		params["height"] = strconv.Itoa(height)
 	} else {
 		height = 450
 	}
 	queryString := this.mapToQueryString(params)
-	jsonString := this.mapToJsonString(params)
+	jsonString, err := this.mapToJsonString(params)
+	if err != nil {
+		return "", err
+	}
 	response := ""
-	if types == "url" {
+	if req.Type == "url" {
 		response = "http://yuntv.letv.com/bcloud.html?" + queryString
 	}
-	if types == "js" {
+	if req.Type == "js" {
 		response = "<script type=\"text/javascript\">var letvcloud_player_conf = " + jsonString + ";</script><script type=\"text/javascript\" src=\"http://yuntv.letv.com/bcloud.js\"></script>"
 	}
-	if types == "flash" {
+	if req.Type == "flash" {
 		response = "http://yuntv.letv.com/bcloud.swf?" + queryString
 	}
-	if types == "html" {
+	if req.Type == "html" {
 
 		response = "<embed src=\"http://yuntv.letv.com/bcloud.swf\" allowFullScreen=\"true\" quality=\"high\" width=\"" + strconv.Itoa(width) + "\" height=\"" + strconv.Itoa(height) + "\" align=\"middle\" allowScriptAccess=\"always\" flashvars=\"" + queryString + "\" type=\"application/x-shockwave-flash\"></embed>"
 	}
-	return response
-}
-
-/**
- * 获取视频播放接口
- * @param string uu 用户唯一标识码，由乐视网统一分配并提供
- * @param string vu 视频唯一标识码
- * @param string type 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
- * @param string pu 播放器唯一标识码
- * @param int auto_play 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
- * @param int width 播放器宽度
- * @return String
- */
-func (this *LetvCloudV1) videoGetPlayinterface_(uu, vu, types, pu string, auto_play, width int) string {
-	return this.videoGetPlayinterface(uu, vu, types, pu, auto_play, width, 0)
-}
-
-/**
- * 获取视频播放接口
- * @param string uu 用户唯一标识码，由乐视网统一分配并提供
- * @param string vu 视频唯一标识码
- * @param string type 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
- * @param string pu 播放器唯一标识码
- * @param int auto_play 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
- * @param int width 播放器宽度
- * @return String
- */
-func (this *LetvCloudV1) videoGetPlayinterface_1(uu, vu, types, pu string, auto_play int) string {
-	return this.videoGetPlayinterface(uu, vu, types, pu, auto_play, 0, 0)
-}
-
-/**
- * 获取视频播放接口
- * @param string uu 用户唯一标识码，由乐视网统一分配并提供
- * @param string vu 视频唯一标识码
- * @param string type 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
- * @param string pu 播放器唯一标识码
- * @param int auto_play 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
- * @param int width 播放器宽度
- * @return String
- */
-func (this *LetvCloudV1) videoGetPlayinterface_2(uu, vu, types, pu string) string {
-	return this.videoGetPlayinterface(uu, vu, types, pu, -1, 0, 0)
-}
-
-/**
- * 获取视频播放接口
- * @param string uu 用户唯一标识码，由乐视网统一分配并提供
- * @param string vu 视频唯一标识码
- * @param string type 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
- * @param string pu 播放器唯一标识码
- * @param int auto_play 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
- * @param int width 播放器宽度
- * @return String
- */
-func (this *LetvCloudV1) videoGetPlayinterface_3(uu, vu, types string) string {
-	return this.videoGetPlayinterface(uu, vu, types, "", -1, 0, 0)
+	return response, nil
 }
 
 /**
@@ -697,7 +616,7 @@ func Int64Tstr(i int64) string {
 }
 
 //构造请求串
-func (this *LetvCloudV1) makeRequest(api string, params map[interface{}]interface{}) []byte {
+func (this *LetvCloudV1) makeRequest(ctx context.Context, api string, params map[interface{}]interface{}) ([]byte, error) {
 	params["user_unique"] = this.userUnique
 	//微秒
 	time := time.Now().UnixNano() / 1000000
@@ -705,14 +624,18 @@ func (this *LetvCloudV1) makeRequest(api string, params map[interface{}]interfac
 	params["ver"] = this.apiVersion
 	params["format"] = this.format
 	params["api"] = api
-	params["sign"] = this.generateSign(params)
+	sign, err := this.generateSign(params)
+	if err != nil {
+		return nil, err
+	}
+	params["sign"] = sign
 	//	params["uploadtype"] = "1"
 	//	params["isdownload"] = "1"
 
 	resurl := ""
 	resurl += this.restUrl + "?" + this.mapToQueryString(params)
 
-	return doGet(resurl)
+	return this.doGet(ctx, resurl)
 }
 
 //将 map 中的参数及对应值转换为查询字符串
@@ -733,111 +656,92 @@ func (this *LetvCloudV1) mapToQueryString(params map[interface{}]interface{}) st
 	return str
 }
 
-func (this *LetvCloudV1) mapToJsonString(params map[interface{}]interface{}) string {
-
-	if bs, err := json.Marshal(params); err != nil {
-		fmt.Println(err)
-		return ""
-	} else {
-		return string(bs)
+//将 map 中的参数转换为JSON字符串
+func (this *LetvCloudV1) mapToJsonString(params map[interface{}]interface{}) (string, error) {
+	m := make(map[string]interface{}, len(params))
+	for k, v := range params {
+		key, ok := k.(string)
+		if !ok {
+			return "", &SignError{Key: fmt.Sprint(k)}
+		}
+		m[key] = v
 	}
-
+	bs, err := json.Marshal(m)
+	if err != nil {
+		return "", err
+	}
+	return string(bs), nil
 }
 
 //GET请求
-func doGet(url string) []byte {
-	c := http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				deadline := time.Now().Add(GTimeOut * time.Second)
-				c, err := net.DialTimeout(netw, addr, time.Second*GTimeOut)
-				if err != nil {
-					return nil, err
-				}
-				c.SetDeadline(deadline)
-				return c, nil
-			},
-		},
-	}
-	var body []byte
-	resp, err := c.Get(url)
+func (this *LetvCloudV1) doGet(ctx context.Context, url string) ([]byte, error) {
+	if this.getTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.getTimeout)
+		defer cancel()
+	}
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, err
+	}
+	return this.do("GET", req)
+}
 
+//发送请求并读取返回内容
+func (this *LetvCloudV1) do(op string, req *http.Request) ([]byte, error) {
+	if len(this.userAgent) > 0 {
+		req.Header.Set("User-Agent", this.userAgent)
+	}
+	resp, err := this.client.Do(req)
 	if err != nil {
-		fmt.Println("error get connection is fail")
-		return nil
+		return nil, &TransportError{Op: op, Url: req.URL.String(), Err: err}
 	}
+	return readResponse(op, req.URL.String(), resp)
+}
 
+//读取返回内容，HTTP状态码非2xx时返回TransportError
+func readResponse(op, url string, resp *http.Response) ([]byte, error) {
 	defer resp.Body.Close()
 
-	body, err = ioutil.ReadAll(resp.Body)
+	body, err := ioutil.ReadAll(resp.Body)
 	if err != nil {
-		fmt.Println("error get connection ioutil.ReadAll")
-		return nil
+		return nil, &TransportError{Op: op, Url: url, StatusCode: resp.StatusCode, Err: err}
 	}
-
-	return body
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, &TransportError{Op: op, Url: url, StatusCode: resp.StatusCode, Err: errors.New(resp.Status)}
+	}
+	return body, nil
 }
 
 //POST上传文件
 
-func (this *LetvCloudV1) doUploadFile(filename, targetUrl string) []byte {
-
-	c := http.Client{
-		Transport: &http.Transport{
-			Dial: func(netw, addr string) (net.Conn, error) {
-				deadline := time.Now().Add(PTimeOut * time.Second)
-				c, err := net.DialTimeout(netw, addr, time.Second*PTimeOut)
-				if err != nil {
-					fmt.Println(err)
-					return nil, err
-				}
-				c.SetDeadline(deadline)
-				return c, nil
-			},
-		},
-	}
-
-	bodyBuf := &bytes.Buffer{}
-	bodyWriter := multipart.NewWriter(bodyBuf)
-
-	//关键的一步操作
-	fileWriter, err := bodyWriter.CreateFormFile("uploadfile", filename)
-	if err != nil {
-		fmt.Println("error writing to buffer")
-		return nil
-	}
-
+func (this *LetvCloudV1) doUploadFile(ctx context.Context, filename, targetUrl string, progress TransferFunc) ([]byte, error) {
 	//打开文件句柄操作
 	fh, err := os.Open(filename)
 	if err != nil {
-		fmt.Println("error opening file")
-		return nil
+		return nil, err
 	}
 	defer fh.Close()
 
-	//iocopy
-	_, err = io.Copy(fileWriter, fh)
+	info, err := fh.Stat()
 	if err != nil {
-		fmt.Println("error io.Copy")
-		return nil
+		return nil, err
 	}
+	meter := newTransferMeter(progress, info.Size())
+	return this.postMultipart(ctx, targetUrl, filepath.Base(filename), meter.reader(fh, 0), info.Size(), nil)
+}
 
-	contentType := bodyWriter.FormDataContentType()
-	bodyWriter.Close()
+//读取时检查ctx，ctx取消后中止读取
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
 
-	resp, err := c.Post(targetUrl, contentType, bodyBuf)
-	if err != nil {
-		fmt.Println("error post connection is fail")
-		return nil
+func (this *contextReader) Read(p []byte) (int, error) {
+	if err := this.ctx.Err(); err != nil {
+		return 0, err
 	}
-	defer resp.Body.Close()
-	resp_body, err := ioutil.ReadAll(resp.Body)
-	if err != nil {
-		fmt.Println(resp_body)
-		return nil
-	}
-
-	return resp_body
+	return this.r.Read(p)
 }
 
 //MD5加密
@@ -0,0 +1,445 @@
+package sdk
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+)
+
+const (
+	testUnique = "test_unique"
+	testSecret = "test_secret"
+)
+
+// 公共参数，每个请求都会带上
+var commonParams = map[string]bool{
+	"user_unique": true,
+	"timestamp":   true,
+	"ver":         true,
+	"format":      true,
+	"api":         true,
+	"sign":        true,
+}
+
+// 模拟接口，记录收到的请求并校验签名
+type fakeApi struct {
+	t       *testing.T
+	server  *httptest.Server
+	respond func(q url.Values) string
+
+	mu      sync.Mutex
+	queries []url.Values
+}
+
+func newFakeApi(t *testing.T, respond func(q url.Values) string) (*fakeApi, *LetvCloudV1) {
+	f := &fakeApi{t: t, respond: respond}
+	f.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		f.mu.Lock()
+		f.queries = append(f.queries, q)
+		f.mu.Unlock()
+		if want := expectedSign(q); q.Get("sign") != want {
+			t.Errorf("%s: sign = %q, want %q", q.Get("api"), q.Get("sign"), want)
+		}
+		w.Write([]byte(f.respond(q)))
+	}))
+	t.Cleanup(f.server.Close)
+	client := NewLetvCloudV1(testUnique, testSecret)
+	client.SetRestUrl(f.server.URL)
+	return f, client
+}
+
+func (this *fakeApi) requests() []url.Values {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return append([]url.Values(nil), this.queries...)
+}
+
+// 按签名规则由收到的参数重新计算sign
+func expectedSign(q url.Values) string {
+	keys := make([]string, 0, len(q))
+	for k := range q {
+		if k != "sign" {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	s := ""
+	for _, k := range keys {
+		s += k + q.Get(k)
+	}
+	sum := md5.Sum([]byte(s + testSecret))
+	return hex.EncodeToString(sum[:])
+}
+
+// 去掉公共参数后的业务参数
+func businessParams(q url.Values) map[string]string {
+	params := make(map[string]string)
+	for k := range q {
+		if !commonParams[k] {
+			params[k] = q.Get(k)
+		}
+	}
+	return params
+}
+
+func okResponse(q url.Values) string {
+	return `{"code":0,"message":"","data":[]}`
+}
+
+func videoResponse(q url.Values) string {
+	return `{"code":0,"data":{"video_id":` + q.Get("video_id") + `,"status":10}}`
+}
+
+func TestEndpointsSendApiAndSignedParams(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name   string
+		call   func(c *LetvCloudV1) error
+		api    string
+		params map[string]string
+		// 为nil时使用okResponse
+		respond func(q url.Values) string
+	}{
+		{
+			name: "VideoUploadInit",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoUploadInit(ctx, "视频 名称", &UploadInitOptions{ClientIp: "1.2.3.4", FileSize: 1024, UploadType: UPLOAD_CHUNKED, IsPay: true})
+				return err
+			},
+			api:    "video.upload.init",
+			params: map[string]string{"video_name": "视频 名称", "client_ip": "1.2.3.4", "file_size": "1024", "uploadtype": "1", "ispay": "1"},
+		},
+		{
+			name: "VideoUploadFlash",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoUploadFlash(ctx, VideoUploadFlashRequest{VideoName: "flash 视频", JsCallback: "done", FlashWidth: 640, FlashHeight: 360, ClientIp: "1.2.3.4"})
+				return err
+			},
+			api:    "video.upload.flash",
+			params: map[string]string{"video_name": "flash 视频", "js_callback": "done", "flash_width": "640", "flash_height": "360", "client_ip": "1.2.3.4"},
+		},
+		{
+			name: "VideoUploadResume",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoUploadResume(ctx, "tok+en/1")
+				return err
+			},
+			api:    "video.upload.resume",
+			params: map[string]string{"token": "tok+en/1"},
+		},
+		{
+			name: "VideoUpdate",
+			call: func(c *LetvCloudV1) error {
+				return c.VideoUpdate(ctx, VideoUpdateRequest{VideoId: 7, VideoName: String("新名称"), Tag: String("a，b, a"), IsPay: Int(1)})
+			},
+			api:    "video.update",
+			params: map[string]string{"video_id": "7", "video_name": "新名称", "tag": "a,b", "is_pay": "1"},
+		},
+		{
+			name: "VideoList",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoList(ctx, VideoListRequest{Index: 2, Size: 20, Status: Status(PLAY_OK)})
+				return err
+			},
+			api:    "video.list",
+			params: map[string]string{"index": "2", "size": "20", "status": "10"},
+		},
+		{
+			name: "VideoGet",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoGet(ctx, 7)
+				return err
+			},
+			api:     "video.get",
+			params:  map[string]string{"video_id": "7"},
+			respond: videoResponse,
+		},
+		{
+			name:   "VideoDel",
+			call:   func(c *LetvCloudV1) error { return c.VideoDel(ctx, 7) },
+			api:    "video.del",
+			params: map[string]string{"video_id": "7"},
+		},
+		{
+			name:   "VideoDelBatch",
+			call:   func(c *LetvCloudV1) error { return c.VideoDelBatch(ctx, []int{1, 2, 3}) },
+			api:    "video.del.batch",
+			params: map[string]string{"video_id_list": "1-2-3"},
+		},
+		{
+			name:   "VideoPause",
+			call:   func(c *LetvCloudV1) error { return c.VideoPause(ctx, 7) },
+			api:    "video.pause",
+			params: map[string]string{"video_id": "7"},
+		},
+		{
+			name:   "VideoRestore",
+			call:   func(c *LetvCloudV1) error { return c.VideoRestore(ctx, 7) },
+			api:    "video.restore",
+			params: map[string]string{"video_id": "7"},
+		},
+		{
+			name: "ImageGet",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.ImageGet(ctx, 7, IMAGE_SIZE_320_240)
+				return err
+			},
+			api:    "image.get",
+			params: map[string]string{"video_id": "7", "size": "320_240"},
+		},
+		{
+			name: "DataVideoHour",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.DataVideoHour(ctx, DataVideoHourRequest{Date: StatDate(2016, 1, 2), Hour: Int(0), VideoId: 7, Index: 1, Size: 100})
+				return err
+			},
+			api:    "data.video.hour",
+			params: map[string]string{"date": "2016-01-02", "hour": "0", "video_id": "7", "index": "1", "size": "100"},
+		},
+		{
+			name: "DataVideoDate",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.DataVideoDate(ctx, DataVideoDateRequest{StartDate: StatDate(2016, 1, 2), EndDate: StatDate(2016, 1, 9), VideoId: 7})
+				return err
+			},
+			api:    "data.video.date",
+			params: map[string]string{"start_date": "2016-01-02", "end_date": "2016-01-09", "video_id": "7"},
+		},
+		{
+			name: "DataTotalDate",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.DataTotalDate(ctx, DataTotalDateRequest{StartDate: StatDate(2016, 1, 2), EndDate: StatDate(2016, 1, 2), Size: 10})
+				return err
+			},
+			api:    "data.total.date",
+			params: map[string]string{"start_date": "2016-01-02", "end_date": "2016-01-02", "size": "10"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			respond := tt.respond
+			if respond == nil {
+				respond = okResponse
+			}
+			f, c := newFakeApi(t, respond)
+			if err := tt.call(c); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			reqs := f.requests()
+			if len(reqs) != 1 {
+				t.Fatalf("got %d requests, want 1", len(reqs))
+			}
+			q := reqs[0]
+			if got := q.Get("api"); got != tt.api {
+				t.Errorf("api = %q, want %q", got, tt.api)
+			}
+			if q.Get("user_unique") != testUnique || q.Get("ver") != "2.0" || q.Get("format") != "json" || q.Get("timestamp") == "" {
+				t.Errorf("bad common params: %v", q)
+			}
+			got := businessParams(q)
+			if len(got) != len(tt.params) {
+				t.Errorf("params = %v, want %v", got, tt.params)
+			}
+			for k, v := range tt.params {
+				if got[k] != v {
+					t.Errorf("param %s = %q, want %q", k, got[k], v)
+				}
+			}
+		})
+	}
+}
+
+func TestApiErrorPassthrough(t *testing.T) {
+	_, c := newFakeApi(t, func(q url.Values) string {
+		return `{"code":123,"message":"bad things"}`
+	})
+	err := c.VideoDel(context.Background(), 1)
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) {
+		t.Fatalf("err = %v, want *APIError", err)
+	}
+	if apiErr.Api != "video.del" || apiErr.Code != 123 || apiErr.Message != "bad things" {
+		t.Errorf("got %+v", apiErr)
+	}
+	if !errors.Is(err, &APIError{Code: 123}) || errors.Is(err, &APIError{Code: 124}) {
+		t.Errorf("errors.Is should compare Code")
+	}
+}
+
+func TestVideoGet(t *testing.T) {
+	tests := []struct {
+		name       string
+		body       string
+		wantErr    error
+		wantStatus VideoStatus
+	}{
+		{name: "found", body: `{"code":0,"data":{"video_id":7,"status":10}}`, wantStatus: PLAY_OK},
+		{name: "missing status", body: `{"code":0,"data":{"video_id":7}}`, wantStatus: UNKNOWN},
+		{name: "empty object", body: `{"code":0,"data":{}}`, wantErr: ErrVideoNotFound},
+		{name: "empty array", body: `{"code":0,"data":[]}`, wantErr: ErrVideoNotFound},
+		{name: "null", body: `{"code":0,"data":null}`, wantErr: ErrVideoNotFound},
+		{name: "no data", body: `{"code":0}`, wantErr: ErrVideoNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, c := newFakeApi(t, func(url.Values) string { return tt.body })
+			video, err := c.VideoGet(context.Background(), 7)
+			if err != tt.wantErr {
+				t.Fatalf("err = %v, want %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if video != nil {
+					t.Errorf("video = %+v, want nil", video)
+				}
+				return
+			}
+			if video.VideoId != 7 || video.Status != tt.wantStatus {
+				t.Errorf("video = %+v, want id 7 status %v", video, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestRegisteredErrorCode(t *testing.T) {
+	RegisterErrorCode(990001, ErrBadSign)
+	t.Cleanup(func() {
+		errorCodesMu.Lock()
+		delete(errorCodes, 990001)
+		errorCodesMu.Unlock()
+	})
+	_, c := newFakeApi(t, func(q url.Values) string {
+		if q.Get("video_id") == "1" {
+			return `{"code":990001,"message":"sign error"}`
+		}
+		return `{"code":990002,"message":"other"}`
+	})
+	err := c.VideoDel(context.Background(), 1)
+	if !errors.Is(err, ErrBadSign) || errors.Is(err, ErrVideoNotFound) {
+		t.Errorf("err = %v, want ErrBadSign only", err)
+	}
+	if err := c.VideoDel(context.Background(), 2); errors.Is(err, ErrBadSign) {
+		t.Errorf("unregistered code matched ErrBadSign: %v", err)
+	}
+}
+
+func TestValidationRejectsBeforeSending(t *testing.T) {
+	ctx := context.Background()
+	tests := []struct {
+		name  string
+		call  func(c *LetvCloudV1) error
+		field string
+	}{
+		{
+			name: "empty video name",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoUploadInit(ctx, "  ", nil)
+				return err
+			},
+			field: "video_name",
+		},
+		{
+			name: "long video name",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoUploadInit(ctx, strings.Repeat("视", MaxVideoNameLength+1), nil)
+				return err
+			},
+			field: "video_name",
+		},
+		{
+			name: "update with bad name",
+			call: func(c *LetvCloudV1) error {
+				return c.VideoUpdate(ctx, VideoUpdateRequest{VideoId: 1, VideoName: String("")})
+			},
+			field: "video_name",
+		},
+		{
+			name: "update with bad is_pay",
+			call: func(c *LetvCloudV1) error {
+				return c.VideoUpdate(ctx, VideoUpdateRequest{VideoId: 1, IsPay: Int(2)})
+			},
+			field: "is_pay",
+		},
+		{
+			name: "update with nothing to change",
+			call: func(c *LetvCloudV1) error {
+				return c.VideoUpdate(ctx, VideoUpdateRequest{VideoId: 1})
+			},
+			field: "video_id",
+		},
+		{
+			name: "list page too large",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoList(ctx, VideoListRequest{Size: MaxPageSize + 1})
+				return err
+			},
+			field: "size",
+		},
+		{
+			name: "list negative index",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.VideoList(ctx, VideoListRequest{Index: -1})
+				return err
+			},
+			field: "index",
+		},
+		{
+			name: "bad hour",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.DataVideoHour(ctx, DataVideoHourRequest{Date: StatDate(2016, 1, 2), Hour: Int(24)})
+				return err
+			},
+			field: "hour",
+		},
+		{
+			name: "reversed range",
+			call: func(c *LetvCloudV1) error {
+				_, err := c.DataTotalDate(ctx, DataTotalDateRequest{StartDate: StatDate(2016, 1, 3), EndDate: StatDate(2016, 1, 2)})
+				return err
+			},
+			field: "start_date",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f, c := newFakeApi(t, okResponse)
+			err := tt.call(c)
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("err = %v, want *ValidationError", err)
+			}
+			if verr.Field != tt.field {
+				t.Errorf("field = %q, want %q", verr.Field, tt.field)
+			}
+			if n := len(f.requests()); n != 0 {
+				t.Errorf("sent %d requests, want 0", n)
+			}
+		})
+	}
+}
+
+func TestFormatTags(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{[]string{"a,b"}, "a,b"},
+		{[]string{" a ，b ", "c"}, "a,b,c"},
+		{[]string{"a", "a,", ",,"}, "a"},
+		{[]string{}, ""},
+	}
+	for _, tt := range tests {
+		if got := FormatTags(tt.in...); got != tt.want {
+			t.Errorf("FormatTags(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
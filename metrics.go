@@ -0,0 +1,284 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// 默认缓存时间，缓存期内的抓取直接返回上次的数据
+	DefaultMetricsCacheTTL = 5 * time.Minute
+	// 默认每次拉取的超时时间
+	DefaultMetricsTimeout = 30 * time.Second
+	// 默认指标名前缀
+	DefaultMetricsPrefix = "letv"
+
+	openMetricsContentType = "application/openmetrics-text; version=1.0.0; charset=utf-8"
+)
+
+/**
+ * MetricsHandler 配置，零值使用默认配置
+ */
+type MetricsOptions struct {
+	// 缓存时间
+	CacheTTL time.Duration
+	// 每次拉取的超时时间
+	Timeout time.Duration
+	// 指标名前缀
+	Prefix string
+	// 补全视频名称，为nil时只使用接口返回的名称
+	Names *VideoNames
+}
+
+func (this *MetricsOptions) withDefaults() MetricsOptions {
+	opts := MetricsOptions{}
+	if this != nil {
+		opts = *this
+	}
+	if opts.CacheTTL <= 0 {
+		opts.CacheTTL = DefaultMetricsCacheTTL
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultMetricsTimeout
+	}
+	if opts.Prefix == "" {
+		opts.Prefix = DefaultMetricsPrefix
+	}
+	return opts
+}
+
+// 一次拉取的结果
+type metricsSnapshot struct {
+	hour   time.Time
+	videos []HourStat
+	day    time.Time
+	total  TotalStat
+}
+
+/**
+ * 以 OpenMetrics 文本格式输出统计数据的 http.Handler
+ * 抓取时数据超过缓存时间则在后台重新拉取上一个完整小时的data.video.hour和当天的data.total.date，
+ * 拉取期间继续输出上次的数据；拉取失败时继续输出上次成功的数据，并将 <prefix>_scrape_success 置为0
+ * 拉取不受抓取请求的超时影响，只受MetricsOptions.Timeout限制，同一时间最多进行一次拉取
+ *
+ *	http.Handle("/metrics", client.NewMetricsHandler(nil))
+ */
+type MetricsHandler struct {
+	client *LetvCloudV1
+	opts   MetricsOptions
+
+	mu          sync.Mutex
+	snapshot    *metricsSnapshot
+	fetchedAt   time.Time
+	succeededAt time.Time
+	err         error
+	// 正在进行的拉取，结束时关闭
+	pending chan struct{}
+}
+
+/**
+ * 创建统计数据指标输出
+ * @param  *MetricsOptions opts 可以为nil
+ * @return *MetricsHandler
+ */
+func (this *LetvCloudV1) NewMetricsHandler(opts *MetricsOptions) *MetricsHandler {
+	return &MetricsHandler{client: this, opts: opts.withDefaults()}
+}
+
+/**
+ * 立即拉取数据，不受缓存时间限制，已有拉取进行中时等待其结束
+ * @param  context.Context ctx 只控制等待，不会取消拉取
+ * @return error
+ */
+func (this *MetricsHandler) Refresh(ctx context.Context) error {
+	this.mu.Lock()
+	done := this.startRefresh()
+	this.mu.Unlock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-done:
+	}
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.err
+}
+
+/**
+ * 按interval在后台定期拉取数据，直到ctx结束
+ * @param  context.Context ctx
+ * @param  time.Duration interval 小于等于0时使用缓存时间
+ */
+func (this *MetricsHandler) Run(ctx context.Context, interval time.Duration) {
+	if interval <= 0 {
+		interval = this.opts.CacheTTL
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		this.Refresh(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (this *MetricsHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	this.mu.Lock()
+	var done chan struct{}
+	if time.Since(this.fetchedAt) >= this.opts.CacheTTL {
+		done = this.startRefresh()
+	}
+	ready := this.snapshot != nil
+	this.mu.Unlock()
+
+	// 还没有任何数据时等待本次拉取
+	if !ready && done != nil {
+		select {
+		case <-r.Context().Done():
+		case <-done:
+		}
+	}
+
+	this.mu.Lock()
+	snapshot, err, succeededAt := this.snapshot, this.err, this.succeededAt
+	this.mu.Unlock()
+	if snapshot == nil {
+		msg := "letv: metrics not ready"
+		if err != nil {
+			msg = err.Error()
+		}
+		http.Error(w, msg, http.StatusServiceUnavailable)
+		return
+	}
+	w.Header().Set("Content-Type", openMetricsContentType)
+	w.Write(this.render(snapshot, err == nil, succeededAt))
+}
+
+// 在后台开始一次拉取，已有拉取进行中时直接返回；调用方需要持有this.mu
+func (this *MetricsHandler) startRefresh() chan struct{} {
+	if this.pending != nil {
+		return this.pending
+	}
+	done := make(chan struct{})
+	this.pending = done
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), this.opts.Timeout)
+		defer cancel()
+		snapshot, err := this.fetch(ctx)
+
+		this.mu.Lock()
+		this.fetchedAt = time.Now()
+		this.err = err
+		if err == nil {
+			this.snapshot = snapshot
+			this.succeededAt = this.fetchedAt
+		}
+		this.pending = nil
+		this.mu.Unlock()
+		close(done)
+	}()
+	return done
+}
+
+func (this *MetricsHandler) fetch(ctx context.Context) (*metricsSnapshot, error) {
+	now := time.Now().In(StatLocation)
+	hour := now.Truncate(time.Hour).Add(-time.Hour)
+	snapshot := &metricsSnapshot{hour: hour, day: StatDay(now), videos: make([]HourStat, 0)}
+
+	it := this.client.ListHourStats(ctx, DataVideoHourRequest{Date: StatDay(hour), Hour: Int(hour.Hour())})
+	for it.Next() {
+		stat := it.Stat()
+		if stat.VideoName == "" && this.opts.Names != nil {
+			name, err := this.opts.Names.Name(ctx, stat.VideoId)
+			if err != nil {
+				return nil, err
+			}
+			stat.VideoName = name
+		}
+		snapshot.videos = append(snapshot.videos, stat)
+	}
+	if err := it.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(snapshot.videos, func(i, j int) bool {
+		return snapshot.videos[i].VideoId < snapshot.videos[j].VideoId
+	})
+
+	totals := this.client.ListTotalStats(ctx, DataTotalDateRequest{StartDate: snapshot.day, EndDate: snapshot.day})
+	for totals.Next() {
+		snapshot.total.PlayCount += totals.Stat().PlayCount
+		snapshot.total.Traffic += totals.Stat().Traffic
+	}
+	if err := totals.Err(); err != nil {
+		return nil, err
+	}
+	snapshot.total.Date = snapshot.day
+	return snapshot, nil
+}
+
+func (this *MetricsHandler) render(snapshot *metricsSnapshot, success bool, succeededAt time.Time) []byte {
+	buf := &bytes.Buffer{}
+	p := this.opts.Prefix
+
+	family(buf, p+"_video_hour_plays", "Play count of each video in the last complete hour.")
+	for _, stat := range snapshot.videos {
+		sample(buf, p+"_video_hour_plays", videoLabels(stat), strconv.FormatInt(stat.PlayCount, 10))
+	}
+	family(buf, p+"_video_hour_traffic", "Traffic of each video in the last complete hour.")
+	for _, stat := range snapshot.videos {
+		sample(buf, p+"_video_hour_traffic", videoLabels(stat), strconv.FormatInt(stat.Traffic, 10))
+	}
+	family(buf, p+"_video_hour_start_seconds", "Start of the hour the per-video samples belong to.")
+	sample(buf, p+"_video_hour_start_seconds", "", strconv.FormatInt(snapshot.hour.Unix(), 10))
+
+	family(buf, p+"_total_day_plays", "Play count of all videos so far today.")
+	sample(buf, p+"_total_day_plays", "", strconv.FormatInt(snapshot.total.PlayCount, 10))
+	family(buf, p+"_total_day_traffic", "Traffic of all videos so far today.")
+	sample(buf, p+"_total_day_traffic", "", strconv.FormatInt(snapshot.total.Traffic, 10))
+
+	family(buf, p+"_scrape_success", "Whether the last pull from the Letv API succeeded.")
+	if success {
+		sample(buf, p+"_scrape_success", "", "1")
+	} else {
+		sample(buf, p+"_scrape_success", "", "0")
+	}
+	family(buf, p+"_last_success_seconds", "Time of the last successful pull from the Letv API.")
+	sample(buf, p+"_last_success_seconds", "", strconv.FormatInt(succeededAt.Unix(), 10))
+
+	buf.WriteString("# EOF\n")
+	return buf.Bytes()
+}
+
+// 输出指标的 TYPE 和 HELP
+func family(buf *bytes.Buffer, name, help string) {
+	buf.WriteString("# TYPE " + name + " gauge\n")
+	buf.WriteString("# HELP " + name + " " + help + "\n")
+}
+
+// 输出一个样本，labels已格式化
+func sample(buf *bytes.Buffer, name, labels, value string) {
+	buf.WriteString(name)
+	if labels != "" {
+		buf.WriteString("{" + labels + "}")
+	}
+	buf.WriteString(" " + value + "\n")
+}
+
+func videoLabels(stat HourStat) string {
+	return `video_id="` + strconv.Itoa(stat.VideoId) + `",video_name="` + escapeLabel(stat.VideoName) + `"`
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escapeLabel(s string) string {
+	return labelEscaper.Replace(s)
+}
@@ -0,0 +1,105 @@
+package sdk
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+const (
+	// 接口请求默认超时时间
+	DefaultGetTimeout = 30 * time.Second
+	// 上传默认不限制总时长，由ctx控制
+	DefaultPostTimeout time.Duration = 0
+	// 建立连接超时时间
+	dialTimeout = 10 * time.Second
+)
+
+/**
+ * NewLetvCloudV1 的可选配置
+ */
+type Option func(*LetvCloudV1)
+
+/**
+ * 使用自定义 http.Client，设置后 WithProxy 不生效
+ * @param *http.Client client
+ * @return Option
+ */
+func WithHTTPClient(client *http.Client) Option {
+	return func(this *LetvCloudV1) {
+		this.client = client
+	}
+}
+
+/**
+ * 设置超时时间，0表示不限制
+ * @param time.Duration get 接口请求超时时间
+ * @param time.Duration post 视频上传超时时间
+ * @return Option
+ */
+func WithTimeouts(get, post time.Duration) Option {
+	return func(this *LetvCloudV1) {
+		this.getTimeout = get
+		this.postTimeout = post
+	}
+}
+
+/**
+ * 设置HTTP代理，默认使用环境变量中的代理设置
+ * @param *url.URL proxy 代理地址
+ * @return Option
+ */
+func WithProxy(proxy *url.URL) Option {
+	return func(this *LetvCloudV1) {
+		this.proxy = proxy
+	}
+}
+
+/**
+ * 设置请求的User-Agent
+ * @param string userAgent
+ * @return Option
+ */
+func WithUserAgent(userAgent string) Option {
+	return func(this *LetvCloudV1) {
+		this.userAgent = userAgent
+	}
+}
+
+/**
+ * 获取上传用户公网IP的方法
+ */
+type ClientIpResolver func(ctx context.Context) (string, error)
+
+/**
+ * 设置获取client_ip的方法，video.upload.init未指定ClientIp时调用
+ * @param ClientIpResolver resolver
+ * @return Option
+ */
+func WithClientIpResolver(resolver ClientIpResolver) Option {
+	return func(this *LetvCloudV1) {
+		this.clientIpResolver = resolver
+	}
+}
+
+// 构造连接池复用的 http.Client
+func newHTTPClient(proxy *url.URL) *http.Client {
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   dialTimeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   10,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
+	if proxy != nil {
+		transport.Proxy = http.ProxyURL(proxy)
+	}
+	return &http.Client{Transport: transport}
+}
@@ -0,0 +1,101 @@
+package sdk
+
+import (
+	"context"
+	"time"
+)
+
+// 默认进度查询间隔
+const DefaultProgressInterval = 2 * time.Second
+
+/**
+ * 服务端确认的上传进度
+ */
+type UploadProgress struct {
+	TotalSize  int64 `json:"total_size"`
+	UploadSize int64 `json:"upload_size"`
+}
+
+/**
+ * 上传百分比，0-100
+ * @return float64
+ */
+func (this *UploadProgress) Percent() float64 {
+	if this.TotalSize <= 0 {
+		return 0
+	}
+	return float64(this.UploadSize) * 100 / float64(this.TotalSize)
+}
+
+/**
+ * 是否已全部上传
+ * @return bool
+ */
+func (this *UploadProgress) Done() bool {
+	return this.TotalSize > 0 && this.UploadSize >= this.TotalSize
+}
+
+/**
+ * 进度查询事件，Err不为nil时查询失败，之后通道关闭
+ */
+type ProgressEvent struct {
+	Progress *UploadProgress
+	Err      error
+}
+
+/**
+ * 视频上传进度查询，只有文件在上传过程中调用才有意义
+ * @param  context.Context ctx 请求上下文
+ * @param  string progress_url 由video.upload.init或video.upload.resume返回的进度查询地址
+ * @return *UploadProgress, error
+ */
+func (this *LetvCloudV1) QueryUploadProgress(ctx context.Context, progress_url string) (*UploadProgress, error) {
+	body, err := this.doGet(ctx, progress_url)
+	if err != nil {
+		return nil, err
+	}
+	result := &UploadProgress{}
+	if _, err := decodeResponse("upload.progress", body, result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+/**
+ * 按时间间隔查询上传进度，上传完成、查询失败或ctx取消后关闭通道
+ * @param  context.Context ctx 请求上下文
+ * @param  string progress_url 进度查询地址
+ * @param  time.Duration interval 查询间隔，小于等于0时使用默认值
+ * @return <-chan ProgressEvent
+ */
+func (this *LetvCloudV1) WatchUploadProgress(ctx context.Context, progress_url string, interval time.Duration) <-chan ProgressEvent {
+	if interval <= 0 {
+		interval = DefaultProgressInterval
+	}
+	events := make(chan ProgressEvent)
+	go func() {
+		defer close(events)
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			progress, err := this.QueryUploadProgress(ctx, progress_url)
+			if ctx.Err() != nil {
+				return
+			}
+			select {
+			case events <- ProgressEvent{Progress: progress, Err: err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil || progress.Done() {
+				return
+			}
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events
+}
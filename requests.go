@@ -0,0 +1,204 @@
+package sdk
+
+import (
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+// 乐视云接口请求参数
+// 指针字段为可选参数，nil表示不传；其它字段零值表示不传
+
+/**
+ * 视频上传（Flash方式）请求参数 (video.upload.flash)
+ */
+type VideoUploadFlashRequest struct {
+	// 视频名称
+	VideoName string
+	// Javascript回调函数，视频上传完毕后调用
+	JsCallback string
+	// Flash宽度，默认值为600
+	FlashWidth int
+	// Flash高度，默认值为450
+	FlashHeight int
+	// 用户IP地址
+	ClientIp string
+}
+
+/**
+ * 视频上传初始化可选参数 (video.upload.init)
+ */
+type UploadInitOptions struct {
+	// 用户IP地址。为了保证用户上传速度，建议将用户公网IP地址写入该参数
+	ClientIp string
+	// 文件大小，单位为字节，上传本地文件时自动填写
+	FileSize int64
+	// 是否分片上传：UPLOAD_NORMAL不分片；UPLOAD_CHUNKED分片
+	UploadType int
+	// 是否支持缓存（离线下载为移动端功能）
+	IsDownload bool
+	// 是否支持DRM（html5 不支持播放加密视频，加密之后不支持离线下载）
+	IsDrm bool
+	// 是否付费（需要客户配置回调地址）
+	IsPay bool
+}
+
+/**
+ * 视频信息更新请求参数 (video.update)
+ */
+type VideoUpdateRequest struct {
+	// 视频ID
+	VideoId int
+	// 视频名称
+	VideoName *string
+	// 视频简介
+	VideoDesc *string
+	// 标签
+	Tag *string
+	// 视频是否收费：0表示不收费；1表示收费（收费视频播放时会进行用户鉴权，请不要随便设置）
+	IsPay *int
+}
+
+/**
+ * 获取视频列表请求参数 (video.list)
+ */
+type VideoListRequest struct {
+	// 开始页索引，默认值为1
+	Index int
+	// 分页大小，默认值为10，最大值为100
+	Size int
+	// 视频状态：ALL表示全部；PLAY_OK表示可以正常播放；FAILED表示处理失败；WAIT表示正在处理过程中。默认值为ALL
+	Status *VideoStatus
+}
+
+/**
+ * 视频小时数据请求参数 (data.video.hour)
+ */
+type DataVideoHourRequest struct {
+	// 日期，按 StatLocation 时区取年月日
+	Date time.Time
+	// 小时，0-23之间，为nil时返回全天数据
+	Hour *int
+	// 视频ID
+	VideoId int
+	// 开始页索引，默认值为1
+	Index int
+	// 分页大小，默认值为10，最大值为100
+	Size int
+}
+
+/**
+ * 视频天数据请求参数 (data.video.date)
+ */
+type DataVideoDateRequest struct {
+	// 开始日期，按 StatLocation 时区取年月日
+	StartDate time.Time
+	// 结束日期，按 StatLocation 时区取年月日，不能早于开始日期
+	EndDate time.Time
+	// 视频ID，不输入该参数将返回所有视频的数据
+	VideoId int
+	// 开始页索引，默认值为1
+	Index int
+	// 分页大小，默认值为10，最大值为100
+	Size int
+}
+
+/**
+ * 所有数据请求参数 (data.total.date)
+ */
+type DataTotalDateRequest struct {
+	// 开始日期，按 StatLocation 时区取年月日
+	StartDate time.Time
+	// 结束日期，按 StatLocation 时区取年月日，不能早于开始日期
+	EndDate time.Time
+	// 开始页索引，默认值为1
+	Index int
+	// 分页大小，默认值为10，最大值为100
+	Size int
+}
+
+/**
+ * 获取视频播放接口请求参数
+ */
+type VideoGetPlayinterfaceRequest struct {
+	// 用户唯一标识码，由乐视网统一分配并提供
+	Uu string
+	// 视频唯一标识码
+	Vu string
+	// 接口类型：url表示播放URL地址；js表示JavaScript代码；flash表示视频地址；html表示HTML代码
+	Type string
+	// 播放器唯一标识码
+	Pu string
+	// 是否自动播放：1表示自动播放；0表示不自动播放。默认值由双方事先约定
+	AutoPlay *int
+	// 播放器宽度，默认值为800
+	Width int
+	// 播放器高度，默认值为450
+	Height int
+}
+
+/**
+ * 返回int指针，用于设置可选参数
+ * @param int v
+ * @return *int
+ */
+func Int(v int) *int {
+	return &v
+}
+
+/**
+ * 返回VideoStatus指针，用于设置可选参数
+ * @param VideoStatus v
+ * @return *VideoStatus
+ */
+func Status(v VideoStatus) *VideoStatus {
+	return &v
+}
+
+/**
+ * 返回string指针，用于设置可选参数
+ * @param string v
+ * @return *string
+ */
+func String(v string) *string {
+	return &v
+}
+
+// 视频名称最大长度
+const MaxVideoNameLength = 200
+
+// 校验视频名称：不能为空，最多200个字符
+func validateVideoName(video_name string) error {
+	if len(strings.TrimSpace(video_name)) == 0 {
+		return &ValidationError{Field: "video_name", Reason: "must not be empty"}
+	}
+	if utf8.RuneCountInString(video_name) > MaxVideoNameLength {
+		return &ValidationError{Field: "video_name", Reason: "longer than " + strconv.Itoa(MaxVideoNameLength) + " characters"}
+	}
+	return nil
+}
+
+/**
+ * 格式化标签：按中英文逗号拆分，去除首尾空白、空标签和重复标签后以英文逗号连接
+ * @param  ...string tags 标签
+ * @return string
+ */
+func FormatTags(tags ...string) string {
+	result := make([]string, 0, len(tags))
+	seen := make(map[string]bool)
+	for _, tag := range tags {
+		for _, t := range strings.FieldsFunc(tag, isTagSeparator) {
+			t = strings.TrimSpace(t)
+			if len(t) > 0 && !seen[t] {
+				seen[t] = true
+				result = append(result, t)
+			}
+		}
+	}
+	return strings.Join(result, ",")
+}
+
+func isTagSeparator(r rune) bool {
+	return r == ',' || r == '，'
+}
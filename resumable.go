@@ -0,0 +1,164 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+/**
+ * 可断点续传的上传任务
+ * 初始化后将token等信息保存到UploadSessionStore，进程重启后再次调用Upload会通过video.upload.resume从已上传位置继续
+ */
+type ResumableUpload struct {
+	client    *LetvCloudV1
+	store     UploadSessionStore
+	videoName string
+	filePath  string
+
+	// 初始化可选参数，可以为nil，file_size和uploadtype自动填写
+	Init *UploadInitOptions
+	// 分片配置，可以为nil
+	Chunk *ChunkOptions
+	// 上传信息创建超过该时间后不再续传，丢弃后重新上传，避免token过期后一直失败；0使用DefaultSessionMaxAge，小于0不限制
+	SessionMaxAge time.Duration
+}
+
+// 上传信息默认有效期
+const DefaultSessionMaxAge = 24 * time.Hour
+
+/**
+ * 创建断点续传上传任务
+ * @param  string video_name 视频名称
+ * @param  string video_file 文件路径，上传信息以其绝对路径保存
+ * @param  UploadSessionStore store 上传信息存储，不能为nil，如 NewFileSessionStore(dir)
+ * @return *ResumableUpload
+ */
+func (this *LetvCloudV1) NewResumableUpload(video_name, video_file string, store UploadSessionStore) *ResumableUpload {
+	return &ResumableUpload{
+		client:    this,
+		store:     store,
+		videoName: video_name,
+		filePath:  video_file,
+	}
+}
+
+/**
+ * 上传视频，存在未完成的上传时从断点继续，上传完成后删除上传信息
+ * @param  context.Context ctx 请求上下文
+ * @return *UploadInitResult, error
+ */
+func (this *ResumableUpload) Upload(ctx context.Context) (*UploadInitResult, error) {
+	if this.store == nil {
+		return nil, &ValidationError{Field: "store", Reason: "must not be nil"}
+	}
+	// 同一文件的不同写法（如a.mp4和./a.mp4）使用同一条上传信息
+	filePath, err := filepath.Abs(this.filePath)
+	if err != nil {
+		return nil, err
+	}
+	checksum, size, err := fileChecksum(filePath)
+	if err != nil {
+		return nil, err
+	}
+	fh, err := os.Open(filePath)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	result, offset, err := this.resume(ctx, filePath, size, checksum)
+	if err != nil {
+		return nil, err
+	}
+	if result == nil {
+		result, err = this.client.videoUploadInit_(ctx, this.videoName, fileInitOptions(this.Init, size, UPLOAD_CHUNKED))
+		if err != nil {
+			return nil, err
+		}
+		now := time.Now()
+		session := &UploadSession{
+			Token:       result.Token,
+			VideoId:     result.VideoId,
+			VideoUnique: result.VideoUnique,
+			VideoName:   this.videoName,
+			FilePath:    filePath,
+			Size:        size,
+			Checksum:    checksum,
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+		if err := this.store.Save(session); err != nil {
+			return nil, err
+		}
+	}
+
+	name := filepath.Base(filePath)
+	if result.UploadType != UPLOAD_CHUNKED {
+		// 服务端不支持分片时从已上传位置整体上传剩余部分
+		meter := newTransferMeter(this.Chunk.withDefaults().OnProgress, size)
+		err = this.client.upload(ctx, result.UploadUrl, name, meter.reader(io.NewSectionReader(fh, offset, size-offset), offset), size-offset, nil)
+	} else {
+		err = this.client.UploadChunks(ctx, fh, offset, size, name, result.UploadUrl, this.Chunk)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := this.store.Delete(filePath); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+/**
+ * 根据已保存的上传信息续传，没有可续传的上传时返回nil
+ * @return *UploadInitResult, int64, error 上传信息, 已上传大小, error
+ */
+func (this *ResumableUpload) resume(ctx context.Context, filePath string, size int64, checksum string) (*UploadInitResult, int64, error) {
+	session, err := this.store.Get(filePath)
+	if err != nil || session == nil {
+		return nil, 0, err
+	}
+	// 文件已变化或上传信息过旧，重新上传
+	if session.Size != size || session.Checksum != checksum || this.sessionTooOld(session) {
+		return nil, 0, this.store.Delete(filePath)
+	}
+
+	resume, err := this.client.videoUploadResume_(ctx, session.Token, UPLOAD_CHUNKED)
+	if err != nil {
+		if errors.Is(err, ErrInvalidToken) {
+			// token已失效，重新上传
+			return nil, 0, this.store.Delete(filePath)
+		}
+		return nil, 0, err
+	}
+	if resume.UploadSize < 0 || resume.UploadSize > size {
+		return nil, 0, this.store.Delete(filePath)
+	}
+
+	session.UpdatedAt = time.Now()
+	if err := this.store.Save(session); err != nil {
+		return nil, 0, err
+	}
+	result := &UploadInitResult{
+		VideoId:     session.VideoId,
+		VideoUnique: session.VideoUnique,
+		UploadUrl:   resume.UploadUrl,
+		ProgressUrl: resume.ProgressUrl,
+		Token:       session.Token,
+		UploadType:  resume.UploadType,
+	}
+	return result, resume.UploadSize, nil
+}
+
+// 创建时间是否超过SessionMaxAge
+func (this *ResumableUpload) sessionTooOld(session *UploadSession) bool {
+	maxAge := this.SessionMaxAge
+	if maxAge == 0 {
+		maxAge = DefaultSessionMaxAge
+	}
+	return maxAge > 0 && time.Since(session.CreatedAt) > maxAge
+}
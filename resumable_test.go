@@ -0,0 +1,236 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const resumableContent = "0123456789"
+
+// 写入测试视频，返回绝对路径
+func writeVideoFile(t *testing.T, dir string) string {
+	path := filepath.Join(dir, "a.mp4")
+	if err := ioutil.WriteFile(path, []byte(resumableContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+// 与path当前内容一致的上传信息
+func savedSession(t *testing.T, path string) *UploadSession {
+	checksum, size, err := fileChecksum(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Now()
+	return &UploadSession{Token: "old-token", VideoId: 3, VideoUnique: "old", FilePath: path, Size: size, Checksum: checksum, CreatedAt: now, UpdatedAt: now}
+}
+
+// 模拟video.upload.init和video.upload.resume，上传地址指向uploadUrl
+func newFakeResumeApi(t *testing.T, uploadUrl, resumeResp string) (*fakeApi, *LetvCloudV1) {
+	return newFakeApi(t, func(q url.Values) string {
+		if q.Get("api") == "video.upload.resume" {
+			return strings.Replace(resumeResp, "UPLOAD_URL", uploadUrl, 1)
+		}
+		return `{"code":0,"data":{"video_id":7,"video_unique":"new","upload_url":"` + uploadUrl + `","token":"new-token","uploadtype":1}}`
+	})
+}
+
+func apiNames(f *fakeApi) []string {
+	names := make([]string, 0)
+	for _, q := range f.requests() {
+		names = append(names, q.Get("api"))
+	}
+	return names
+}
+
+func TestResumableUpload(t *testing.T) {
+	RegisterErrorCode(990003, ErrInvalidToken)
+	t.Cleanup(func() {
+		errorCodesMu.Lock()
+		delete(errorCodes, 990003)
+		errorCodesMu.Unlock()
+	})
+
+	tests := []struct {
+		name string
+		// 修改已保存的上传信息，为nil时没有上传信息
+		session    func(s *UploadSession)
+		maxAge     time.Duration
+		resumeResp string
+		wantApis   []string
+		wantRanges []string
+		// 按上传顺序拼接的内容
+		wantData    string
+		wantVideoId int
+		wantErr     bool
+		// 结束后是否还有上传信息
+		wantSession bool
+	}{
+		{
+			name:        "fresh upload",
+			wantApis:    []string{"video.upload.init"},
+			wantRanges:  []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    resumableContent,
+			wantVideoId: 7,
+		},
+		{
+			name:        "resumes chunks from upload_size",
+			session:     func(s *UploadSession) {},
+			resumeResp:  `{"code":0,"data":{"upload_url":"UPLOAD_URL","upload_size":4,"uploadtype":1}}`,
+			wantApis:    []string{"video.upload.resume"},
+			wantRanges:  []string{"bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    "456789",
+			wantVideoId: 3,
+		},
+		{
+			name:        "uploadtype 0 falls back to a single post",
+			session:     func(s *UploadSession) {},
+			resumeResp:  `{"code":0,"data":{"upload_url":"UPLOAD_URL","upload_size":6,"uploadtype":0}}`,
+			wantApis:    []string{"video.upload.resume"},
+			wantRanges:  []string{""},
+			wantData:    "6789",
+			wantVideoId: 3,
+		},
+		{
+			name:        "checksum mismatch re-inits",
+			session:     func(s *UploadSession) { s.Checksum = "changed" },
+			wantApis:    []string{"video.upload.init"},
+			wantRanges:  []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    resumableContent,
+			wantVideoId: 7,
+		},
+		{
+			name:        "stale session re-inits",
+			session:     func(s *UploadSession) { s.CreatedAt = time.Now().Add(-2 * time.Hour) },
+			maxAge:      time.Hour,
+			wantApis:    []string{"video.upload.init"},
+			wantRanges:  []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    resumableContent,
+			wantVideoId: 7,
+		},
+		{
+			name:        "invalid token re-inits",
+			session:     func(s *UploadSession) {},
+			resumeResp:  `{"code":990003,"message":"token expired"}`,
+			wantApis:    []string{"video.upload.resume", "video.upload.init"},
+			wantRanges:  []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    resumableContent,
+			wantVideoId: 7,
+		},
+		{
+			name:        "upload_size beyond file re-inits",
+			session:     func(s *UploadSession) {},
+			resumeResp:  `{"code":0,"data":{"upload_url":"UPLOAD_URL","upload_size":11,"uploadtype":1}}`,
+			wantApis:    []string{"video.upload.resume", "video.upload.init"},
+			wantRanges:  []string{"bytes 0-3/10", "bytes 4-7/10", "bytes 8-9/10"},
+			wantData:    resumableContent,
+			wantVideoId: 7,
+		},
+		{
+			name:        "other api errors keep the session",
+			session:     func(s *UploadSession) {},
+			resumeResp:  `{"code":7,"message":"busy"}`,
+			wantApis:    []string{"video.upload.resume"},
+			wantRanges:  []string{},
+			wantErr:     true,
+			wantSession: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := writeVideoFile(t, t.TempDir())
+			store := NewMemorySessionStore()
+			if tt.session != nil {
+				session := savedSession(t, path)
+				tt.session(session)
+				store.Save(session)
+			}
+			upload, uploadUrl := newFakeUpload(t, func(string, int) (int, string) { return 0, "" })
+			f, c := newFakeResumeApi(t, uploadUrl, tt.resumeResp)
+
+			r := c.NewResumableUpload("a", path, store)
+			r.Chunk = &ChunkOptions{ChunkSize: 4}
+			r.SessionMaxAge = tt.maxAge
+			// 上传过程中上传信息一直保存着
+			r.Chunk.OnChunk = func(ChunkInfo) {
+				if s, _ := store.Get(path); s == nil {
+					t.Errorf("session missing during upload")
+				}
+			}
+			result, err := r.Upload(context.Background())
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && result.VideoId != tt.wantVideoId {
+				t.Errorf("video_id = %d, want %d", result.VideoId, tt.wantVideoId)
+			}
+			if got := apiNames(f); strings.Join(got, ",") != strings.Join(tt.wantApis, ",") {
+				t.Errorf("apis = %v, want %v", got, tt.wantApis)
+			}
+			if strings.Join(upload.ranges, ",") != strings.Join(tt.wantRanges, ",") {
+				t.Errorf("ranges = %q, want %q", upload.ranges, tt.wantRanges)
+			}
+			data := ""
+			for _, cr := range upload.ranges {
+				data += string(upload.data[cr])
+			}
+			if data != tt.wantData {
+				t.Errorf("uploaded %q, want %q", data, tt.wantData)
+			}
+			if s, _ := store.Get(path); (s != nil) != tt.wantSession {
+				t.Errorf("session = %+v, want kept %v", s, tt.wantSession)
+			}
+		})
+	}
+}
+
+func TestResumableUploadRequiresStore(t *testing.T) {
+	path := writeVideoFile(t, t.TempDir())
+	f, c := newFakeApi(t, okResponse)
+	_, err := c.NewResumableUpload("a", path, nil).Upload(context.Background())
+	var verr *ValidationError
+	if !errors.As(err, &verr) || verr.Field != "store" {
+		t.Errorf("err = %v, want ValidationError for store", err)
+	}
+	if n := len(f.requests()); n != 0 {
+		t.Errorf("sent %d requests, want 0", n)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(path), ".letv-upload")); !os.IsNotExist(err) {
+		t.Errorf("created a session directory next to the video")
+	}
+}
+
+func TestResumableUploadKeysOnAbsolutePath(t *testing.T) {
+	dir := t.TempDir()
+	path := writeVideoFile(t, dir)
+	t.Chdir(dir)
+
+	store := NewMemorySessionStore()
+	store.Save(savedSession(t, path))
+	upload, uploadUrl := newFakeUpload(t, func(string, int) (int, string) { return 0, "" })
+	f, c := newFakeResumeApi(t, uploadUrl, `{"code":0,"data":{"upload_url":"UPLOAD_URL","upload_size":8,"uploadtype":1}}`)
+
+	r := c.NewResumableUpload("a", "./a.mp4", store)
+	r.Chunk = &ChunkOptions{ChunkSize: 4}
+	if _, err := r.Upload(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if got := apiNames(f); len(got) != 1 || got[0] != "video.upload.resume" {
+		t.Errorf("apis = %v, want [video.upload.resume]", got)
+	}
+	if len(upload.ranges) != 1 || upload.ranges[0] != "bytes 8-9/10" {
+		t.Errorf("ranges = %v", upload.ranges)
+	}
+	if sessions, _ := store.List(); len(sessions) != 0 {
+		t.Errorf("sessions left: %+v", sessions)
+	}
+}
@@ -0,0 +1,323 @@
+package sdk
+
+import (
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 计算校验值时读取文件首尾的字节数
+const sessionChecksumBytes = 1 << 20
+
+/**
+ * 未完成的上传，用于进程重启后续传
+ */
+type UploadSession struct {
+	Token       string    `json:"token"`
+	VideoId     int       `json:"video_id"`
+	VideoUnique string    `json:"video_unique"`
+	VideoName   string    `json:"video_name"`
+	FilePath    string    `json:"file_path"`
+	Size        int64     `json:"size"`
+	Checksum    string    `json:"checksum"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+/**
+ * 是否超过maxAge未更新
+ * @param  time.Duration maxAge
+ * @return bool
+ */
+func (this *UploadSession) Expired(maxAge time.Duration) bool {
+	return time.Since(this.UpdatedAt) > maxAge
+}
+
+/**
+ * 上传信息存储，以FilePath为key，ResumableUpload保存的FilePath为绝对路径
+ * Get 在不存在时返回 nil, nil
+ */
+type UploadSessionStore interface {
+	Get(filePath string) (*UploadSession, error)
+	Save(session *UploadSession) error
+	Delete(filePath string) error
+	List() ([]*UploadSession, error)
+}
+
+/**
+ * 删除超过maxAge未更新的上传信息
+ * @param  UploadSessionStore store
+ * @param  time.Duration maxAge
+ * @return []*UploadSession, error 被删除的上传信息
+ */
+func ExpireUploadSessions(store UploadSessionStore, maxAge time.Duration) ([]*UploadSession, error) {
+	return removeSessions(store, func(session *UploadSession) bool {
+		return session.Expired(maxAge)
+	})
+}
+
+/**
+ * 清理已放弃的上传：超过maxAge未更新，或本地文件已删除、已修改
+ * 使用 FileSessionStore 时同时删除已损坏的文件和Save中途崩溃留下的临时文件
+ * @param  UploadSessionStore store
+ * @param  time.Duration maxAge
+ * @return []*UploadSession, error 被删除的上传信息
+ */
+func GCUploadSessions(store UploadSessionStore, maxAge time.Duration) ([]*UploadSession, error) {
+	removed, err := removeSessions(store, func(session *UploadSession) bool {
+		if session.Expired(maxAge) {
+			return true
+		}
+		checksum, size, err := fileChecksum(session.FilePath)
+		return err != nil || size != session.Size || checksum != session.Checksum
+	})
+	if err != nil {
+		return removed, err
+	}
+	if p, ok := store.(sessionPurger); ok {
+		err = p.purge()
+	}
+	return removed, err
+}
+
+// 存储自身的清理，如删除已损坏的文件，List无法返回这些数据
+type sessionPurger interface {
+	purge() error
+}
+
+func removeSessions(store UploadSessionStore, match func(*UploadSession) bool) ([]*UploadSession, error) {
+	sessions, err := store.List()
+	if err != nil {
+		return nil, err
+	}
+	removed := make([]*UploadSession, 0)
+	for _, session := range sessions {
+		if !match(session) {
+			continue
+		}
+		if err := store.Delete(session.FilePath); err != nil {
+			return removed, err
+		}
+		removed = append(removed, session)
+	}
+	return removed, nil
+}
+
+/**
+ * 文件校验值：文件大小及首尾各1MB内容的MD5，避免大文件全量计算
+ * @param  string filePath
+ * @return string, int64, error 校验值, 文件大小, error
+ */
+func fileChecksum(filePath string) (string, int64, error) {
+	fh, err := os.Open(filePath)
+	if err != nil {
+		return "", 0, err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return "", 0, err
+	}
+	size := info.Size()
+
+	h := md5.New()
+	io.WriteString(h, strconv.FormatInt(size, 10))
+	if _, err := io.Copy(h, io.NewSectionReader(fh, 0, sessionChecksumBytes)); err != nil {
+		return "", 0, err
+	}
+	if size > sessionChecksumBytes {
+		tail := size - sessionChecksumBytes
+		if tail < sessionChecksumBytes {
+			tail = sessionChecksumBytes
+		}
+		if _, err := io.Copy(h, io.NewSectionReader(fh, tail, sessionChecksumBytes)); err != nil {
+			return "", 0, err
+		}
+	}
+	return hex.EncodeToString(h.Sum(nil)), size, nil
+}
+
+/**
+ * 内存存储，进程退出后丢失，适用于测试或单进程内重试
+ */
+type MemorySessionStore struct {
+	mu       sync.Mutex
+	sessions map[string]UploadSession
+}
+
+func NewMemorySessionStore() *MemorySessionStore {
+	return &MemorySessionStore{sessions: make(map[string]UploadSession)}
+}
+
+func (this *MemorySessionStore) Get(filePath string) (*UploadSession, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	session, ok := this.sessions[filePath]
+	if !ok {
+		return nil, nil
+	}
+	return &session, nil
+}
+
+func (this *MemorySessionStore) Save(session *UploadSession) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.sessions[session.FilePath] = *session
+	return nil
+}
+
+func (this *MemorySessionStore) Delete(filePath string) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	delete(this.sessions, filePath)
+	return nil
+}
+
+func (this *MemorySessionStore) List() ([]*UploadSession, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	sessions := make([]*UploadSession, 0, len(this.sessions))
+	for _, session := range this.sessions {
+		s := session
+		sessions = append(sessions, &s)
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+/**
+ * 文件存储，每个上传保存为目录下的一个JSON文件
+ */
+type FileSessionStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+// 上传信息文件后缀
+const sessionFileSuffix = ".json"
+
+/**
+ * 创建文件存储，目录不存在时自动创建
+ * @param  string dir 存储目录
+ * @return *FileSessionStore, error
+ */
+func NewFileSessionStore(dir string) (*FileSessionStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	return &FileSessionStore{dir: dir}, nil
+}
+
+// 以文件路径的MD5作为文件名
+func (this *FileSessionStore) path(filePath string) string {
+	sum := md5.Sum([]byte(filePath))
+	return filepath.Join(this.dir, hex.EncodeToString(sum[:])+sessionFileSuffix)
+}
+
+func (this *FileSessionStore) Get(filePath string) (*UploadSession, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return readSessionFile(this.path(filePath))
+}
+
+func (this *FileSessionStore) Save(session *UploadSession) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return writeJSONFile(this.path(session.FilePath), session)
+}
+
+func (this *FileSessionStore) Delete(filePath string) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	err := os.Remove(this.path(filePath))
+	if os.IsNotExist(err) {
+		return nil
+	}
+	return err
+}
+
+func (this *FileSessionStore) List() ([]*UploadSession, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	files, err := ioutil.ReadDir(this.dir)
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*UploadSession, 0, len(files))
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), sessionFileSuffix) {
+			continue
+		}
+		session, err := readSessionFile(filepath.Join(this.dir, f.Name()))
+		if err != nil {
+			return nil, err
+		}
+		if session != nil {
+			sessions = append(sessions, session)
+		}
+	}
+	sortSessions(sessions)
+	return sessions, nil
+}
+
+// 删除无法解析的上传信息文件和崩溃遗留的临时文件
+func (this *FileSessionStore) purge() error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	if err := removeStaleTmpFiles(this.dir); err != nil {
+		return err
+	}
+	files, err := ioutil.ReadDir(this.dir)
+	if err != nil {
+		return err
+	}
+	for _, f := range files {
+		if f.IsDir() || !strings.HasSuffix(f.Name(), sessionFileSuffix) {
+			continue
+		}
+		path := filepath.Join(this.dir, f.Name())
+		session, err := readSessionFile(path)
+		if err != nil {
+			return err
+		}
+		if session == nil {
+			if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// 文件不存在或已损坏时返回nil
+func readSessionFile(path string) (*UploadSession, error) {
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	session := &UploadSession{}
+	if err := json.Unmarshal(data, session); err != nil {
+		return nil, nil
+	}
+	return session, nil
+}
+
+// 按创建时间排序
+func sortSessions(sessions []*UploadSession) {
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].CreatedAt.Before(sessions[j].CreatedAt)
+	})
+}
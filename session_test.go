@@ -0,0 +1,116 @@
+package sdk
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestFileSessionStoreRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	store, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if s, err := store.Get("/videos/a.mp4"); s != nil || err != nil {
+		t.Fatalf("Get on empty store = %+v, %v", s, err)
+	}
+
+	now := time.Now().Truncate(time.Second)
+	a := &UploadSession{Token: "t1", VideoId: 1, FilePath: "/videos/a.mp4", Size: 10, Checksum: "c1", CreatedAt: now, UpdatedAt: now}
+	b := &UploadSession{Token: "t2", VideoId: 2, FilePath: "/videos/b.mp4", Size: 20, Checksum: "c2", CreatedAt: now.Add(time.Second), UpdatedAt: now}
+	for _, s := range []*UploadSession{b, a} {
+		if err := store.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	// 覆盖保存
+	a.Token = "t1b"
+	if err := store.Save(a); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := store.Get("/videos/a.mp4")
+	if err != nil || got == nil || got.Token != "t1b" || got.VideoId != 1 || !got.CreatedAt.Equal(now) {
+		t.Errorf("Get = %+v, %v", got, err)
+	}
+	list, err := store.List()
+	if err != nil || len(list) != 2 || list[0].VideoId != 1 || list[1].VideoId != 2 {
+		t.Errorf("List = %+v, %v", list, err)
+	}
+	files, _ := ioutil.ReadDir(dir)
+	for _, f := range files {
+		if filepath.Ext(f.Name()) != sessionFileSuffix {
+			t.Errorf("unexpected file %s after Save", f.Name())
+		}
+	}
+
+	if err := store.Delete("/videos/a.mp4"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Delete("/videos/a.mp4"); err != nil {
+		t.Errorf("deleting twice: %v", err)
+	}
+	if list, _ := store.List(); len(list) != 1 || list[0].VideoId != 2 {
+		t.Errorf("List after Delete = %+v", list)
+	}
+}
+
+func TestGCUploadSessions(t *testing.T) {
+	videos := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(videos, name)
+		if err := ioutil.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	keep := savedSession(t, write("keep.mp4", "keep"))
+	expired := savedSession(t, write("expired.mp4", "expired"))
+	expired.UpdatedAt = time.Now().Add(-2 * time.Hour)
+	changed := savedSession(t, write("changed.mp4", "before"))
+	write("changed.mp4", "after!")
+	deleted := savedSession(t, write("deleted.mp4", "deleted"))
+	os.Remove(deleted.FilePath)
+
+	dir := t.TempDir()
+	store, err := NewFileSessionStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []*UploadSession{keep, expired, changed, deleted} {
+		if err := store.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+	corrupt := filepath.Join(dir, "corrupt"+sessionFileSuffix)
+	staleTmp := filepath.Join(dir, "stale"+sessionFileSuffix+tmpFileSuffix)
+	freshTmp := filepath.Join(dir, "fresh"+sessionFileSuffix+tmpFileSuffix)
+	for _, path := range []string{corrupt, staleTmp, freshTmp} {
+		if err := ioutil.WriteFile(path, []byte(`{"token":`), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * tmpFileMaxAge)
+	os.Chtimes(staleTmp, old, old)
+
+	removed, err := GCUploadSessions(store, time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(removed) != 3 {
+		t.Errorf("removed %d sessions, want 3: %+v", len(removed), removed)
+	}
+	list, err := store.List()
+	if err != nil || len(list) != 1 || list[0].FilePath != keep.FilePath {
+		t.Errorf("List after GC = %+v, %v", list, err)
+	}
+	for path, want := range map[string]bool{corrupt: false, staleTmp: false, freshTmp: true} {
+		_, err := os.Stat(path)
+		if exists := err == nil; exists != want {
+			t.Errorf("%s exists = %v, want %v", filepath.Base(path), exists, want)
+		}
+	}
+}
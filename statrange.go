@@ -0,0 +1,166 @@
+package sdk
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
+
+// 每次请求的最大日期跨度（天），超过后拆分为多次请求
+const MaxStatRangeDays = 31
+
+/**
+ * FetchDateStats / FetchTotalStats 配置，零值使用默认配置
+ */
+type StatRangeOptions struct {
+	// 每段最大天数，默认为MaxStatRangeDays
+	MaxDays int
+	// 同时请求的段数，默认为DefaultBulkConcurrency
+	Concurrency int
+}
+
+func (this *StatRangeOptions) withDefaults() StatRangeOptions {
+	opts := StatRangeOptions{}
+	if this != nil {
+		opts = *this
+	}
+	if opts.MaxDays <= 0 {
+		opts.MaxDays = MaxStatRangeDays
+	}
+	return opts
+}
+
+// 闭区间日期段
+type statRange struct {
+	start time.Time
+	end   time.Time
+}
+
+// 将[start, end]拆分为不超过maxDays天的连续日期段
+func splitStatRange(start, end time.Time, maxDays int) []statRange {
+	start, end = StatDay(start), StatDay(end)
+	ranges := make([]statRange, 0)
+	for !start.After(end) {
+		next := start.AddDate(0, 0, maxDays)
+		last := next.AddDate(0, 0, -1)
+		if last.After(end) {
+			last = end
+		}
+		ranges = append(ranges, statRange{start: start, end: last})
+		start = next
+	}
+	return ranges
+}
+
+/**
+ * 按段并发执行fetch，任意一段失败时取消其余请求并返回第一个错误
+ * @param  context.Context ctx 请求上下文
+ * @param  []statRange ranges 日期段
+ * @param  int concurrency 并发数
+ * @param  func fetch 请求第i段
+ * @return error
+ */
+func fetchStatRanges(ctx context.Context, ranges []statRange, concurrency int, fetch func(ctx context.Context, i int, r statRange) error) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var first error
+	runBulk(len(ranges), concurrency, func(i int) {
+		if ctx.Err() != nil {
+			return
+		}
+		if err := fetch(ctx, i, ranges[i]); err != nil {
+			mu.Lock()
+			if first == nil {
+				first = err
+				cancel()
+			}
+			mu.Unlock()
+		}
+	})
+	if first != nil {
+		return first
+	}
+	return ctx.Err()
+}
+
+/**
+ * 获取任意日期跨度的视频天数据，自动拆分日期段并遍历所有分页，结果按日期、视频ID排序
+ * @param  context.Context ctx 请求上下文
+ * @param  DataVideoDateRequest req 查询条件，Index和Size被忽略
+ * @param  *StatRangeOptions opts 可以为nil
+ * @return []DateStat, error
+ */
+func (this *LetvCloudV1) FetchDateStats(ctx context.Context, req DataVideoDateRequest, opts *StatRangeOptions) ([]DateStat, error) {
+	if err := validateStatRange(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	o := opts.withDefaults()
+	ranges := splitStatRange(req.StartDate, req.EndDate, o.MaxDays)
+	parts := make([][]DateStat, len(ranges))
+	err := fetchStatRanges(ctx, ranges, o.Concurrency, func(ctx context.Context, i int, r statRange) error {
+		sub := req
+		sub.StartDate, sub.EndDate = r.start, r.end
+		sub.Index, sub.Size = 0, 0
+		it := this.ListDateStats(ctx, sub)
+		for it.Next() {
+			parts[i] = append(parts[i], it.Stat())
+		}
+		return it.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]DateStat, 0)
+	for _, part := range parts {
+		stats = append(stats, part...)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		if !stats[i].Date.Equal(stats[j].Date) {
+			return stats[i].Date.Before(stats[j].Date)
+		}
+		return stats[i].VideoId < stats[j].VideoId
+	})
+	return stats, nil
+}
+
+/**
+ * 获取任意日期跨度的所有数据，自动拆分日期段并遍历所有分页，结果按日期排序
+ * @param  context.Context ctx 请求上下文
+ * @param  DataTotalDateRequest req 查询条件，Index和Size被忽略
+ * @param  *StatRangeOptions opts 可以为nil
+ * @return []TotalStat, error
+ */
+func (this *LetvCloudV1) FetchTotalStats(ctx context.Context, req DataTotalDateRequest, opts *StatRangeOptions) ([]TotalStat, error) {
+	if err := validateStatRange(req.StartDate, req.EndDate); err != nil {
+		return nil, err
+	}
+	o := opts.withDefaults()
+	ranges := splitStatRange(req.StartDate, req.EndDate, o.MaxDays)
+	parts := make([][]TotalStat, len(ranges))
+	err := fetchStatRanges(ctx, ranges, o.Concurrency, func(ctx context.Context, i int, r statRange) error {
+		sub := req
+		sub.StartDate, sub.EndDate = r.start, r.end
+		sub.Index, sub.Size = 0, 0
+		it := this.ListTotalStats(ctx, sub)
+		for it.Next() {
+			parts[i] = append(parts[i], it.Stat())
+		}
+		return it.Err()
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	stats := make([]TotalStat, 0)
+	for _, part := range parts {
+		stats = append(stats, part...)
+	}
+	sort.SliceStable(stats, func(i, j int) bool {
+		return stats[i].Date.Before(stats[j].Date)
+	})
+	return stats, nil
+}
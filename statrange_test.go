@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"context"
+	"net/url"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestSplitStatRange(t *testing.T) {
+	d := StatDate
+	tests := []struct {
+		name    string
+		start   time.Time
+		end     time.Time
+		maxDays int
+		want    [][2]string
+	}{
+		{name: "single day", start: d(2016, 1, 1), end: d(2016, 1, 1), maxDays: 31, want: [][2]string{{"2016-01-01", "2016-01-01"}}},
+		{name: "exactly max days", start: d(2016, 1, 1), end: d(2016, 1, 31), maxDays: 31, want: [][2]string{{"2016-01-01", "2016-01-31"}}},
+		{name: "one day over", start: d(2016, 1, 1), end: d(2016, 2, 1), maxDays: 31, want: [][2]string{{"2016-01-01", "2016-01-31"}, {"2016-02-01", "2016-02-01"}}},
+		{name: "one day per range", start: d(2016, 2, 28), end: d(2016, 3, 1), maxDays: 1, want: [][2]string{{"2016-02-28", "2016-02-28"}, {"2016-02-29", "2016-02-29"}, {"2016-03-01", "2016-03-01"}}},
+		{name: "start after end", start: d(2016, 1, 2), end: d(2016, 1, 1), maxDays: 31, want: [][2]string{}},
+		{
+			name:    "times are truncated to days in StatLocation",
+			start:   time.Date(2016, 1, 1, 20, 0, 0, 0, time.UTC),
+			end:     time.Date(2016, 1, 3, 15, 59, 0, 0, time.UTC),
+			maxDays: 31,
+			want:    [][2]string{{"2016-01-02", "2016-01-03"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitStatRange(tt.start, tt.end, tt.maxDays)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d ranges, want %d: %v", len(got), len(tt.want), got)
+			}
+			for i, r := range got {
+				if s, e := formatStatDate(r.start), formatStatDate(r.end); s != tt.want[i][0] || e != tt.want[i][1] {
+					t.Errorf("range %d = %s..%s, want %s..%s", i, s, e, tt.want[i][0], tt.want[i][1])
+				}
+			}
+		})
+	}
+}
+
+func TestSplitStatRangeCoversYear(t *testing.T) {
+	start, end := StatDate(2016, 1, 1), StatDate(2016, 12, 31)
+	ranges := splitStatRange(start, end, MaxStatRangeDays)
+	if len(ranges) != 12 {
+		t.Fatalf("got %d ranges, want 12", len(ranges))
+	}
+	next := start
+	for i, r := range ranges {
+		if !r.start.Equal(next) {
+			t.Errorf("range %d starts %v, want %v", i, r.start, next)
+		}
+		if days := int(r.end.Sub(r.start).Hours()/24) + 1; days > MaxStatRangeDays || days < 1 {
+			t.Errorf("range %d has %d days", i, days)
+		}
+		next = r.end.AddDate(0, 0, 1)
+	}
+	if !ranges[len(ranges)-1].end.Equal(end) {
+		t.Errorf("last range ends %v, want %v", ranges[len(ranges)-1].end, end)
+	}
+}
+
+func TestFetchTotalStatsMergesRanges(t *testing.T) {
+	var calls int32
+	f, c := newFakeApi(t, func(q url.Values) string {
+		atomic.AddInt32(&calls, 1)
+		// 每段一页，倒序返回开始和结束日期
+		return `{"code":0,"total":2,"data":[{"date":"` + q.Get("end_date") + `","vv":1},{"date":"` + q.Get("start_date") + `","vv":2}]}`
+	})
+	stats, err := c.FetchTotalStats(context.Background(), DataTotalDateRequest{StartDate: StatDate(2016, 1, 1), EndDate: StatDate(2016, 1, 10)}, &StatRangeOptions{MaxDays: 3, Concurrency: 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4段：1-3, 4-6, 7-9, 10-10
+	if calls != 4 {
+		t.Errorf("calls = %d, want 4", calls)
+	}
+	for _, q := range f.requests() {
+		if q.Get("size") != "100" {
+			t.Errorf("size = %q, want 100", q.Get("size"))
+		}
+	}
+	want := []string{"2016-01-01", "2016-01-03", "2016-01-04", "2016-01-06", "2016-01-07", "2016-01-09", "2016-01-10", "2016-01-10"}
+	if len(stats) != len(want) {
+		t.Fatalf("got %d stats, want %d", len(stats), len(want))
+	}
+	for i, s := range stats {
+		if got := formatStatDate(s.Date); got != want[i] {
+			t.Errorf("stat %d date = %s, want %s", i, got, want[i])
+		}
+	}
+}
+
+func TestFetchTotalStatsReturnsFirstError(t *testing.T) {
+	_, c := newFakeApi(t, func(q url.Values) string {
+		if q.Get("start_date") == "2016-01-04" {
+			return `{"code":5,"message":"range too long"}`
+		}
+		return `{"code":0,"total":0,"data":[]}`
+	})
+	_, err := c.FetchTotalStats(context.Background(), DataTotalDateRequest{StartDate: StatDate(2016, 1, 1), EndDate: StatDate(2016, 1, 9)}, &StatRangeOptions{MaxDays: 3})
+	apiErr, ok := err.(*APIError)
+	if !ok || apiErr.Code != 5 {
+		t.Errorf("err = %v, want APIError code 5", err)
+	}
+}
@@ -0,0 +1,233 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"time"
+)
+
+// 统计接口日期格式
+const StatDateLayout = "2006-01-02"
+
+// 统计接口使用的时区（Asia/Shanghai），系统缺少时区数据时使用UTC+8
+var StatLocation = loadStatLocation()
+
+func loadStatLocation() *time.Location {
+	if loc, err := time.LoadLocation("Asia/Shanghai"); err == nil {
+		return loc
+	}
+	return time.FixedZone("CST", 8*3600)
+}
+
+/**
+ * StatLocation 时区中某一天的零点
+ * @param  int year
+ * @param  time.Month month
+ * @param  int day
+ * @return time.Time
+ */
+func StatDate(year int, month time.Month, day int) time.Time {
+	return time.Date(year, month, day, 0, 0, 0, 0, StatLocation)
+}
+
+/**
+ * 换算到 StatLocation 时区后所在那一天的零点
+ * @param  time.Time t
+ * @return time.Time
+ */
+func StatDay(t time.Time) time.Time {
+	t = t.In(StatLocation)
+	return StatDate(t.Year(), t.Month(), t.Day())
+}
+
+/**
+ * 按 StatDateLayout 解析 StatLocation 时区的日期
+ * @param  string s 格式为：yyyy-mm-dd
+ * @return time.Time, error
+ */
+func ParseStatDate(s string) (time.Time, error) {
+	return time.ParseInLocation(StatDateLayout, s, StatLocation)
+}
+
+// 按接口格式输出日期，零值输出空字符串
+func formatStatDate(t time.Time) string {
+	if t.IsZero() {
+		return ""
+	}
+	return t.In(StatLocation).Format(StatDateLayout)
+}
+
+// 校验日期不为零值
+func validateStatDate(field string, t time.Time) error {
+	if t.IsZero() {
+		return &ValidationError{Field: field, Reason: "must be set"}
+	}
+	return nil
+}
+
+// 校验开始日期不晚于结束日期
+func validateStatRange(start, end time.Time) error {
+	if err := validateStatDate("start_date", start); err != nil {
+		return err
+	}
+	if err := validateStatDate("end_date", end); err != nil {
+		return err
+	}
+	if StatDay(start).After(StatDay(end)) {
+		return &ValidationError{Field: "start_date", Reason: "after end_date"}
+	}
+	return nil
+}
+
+// 校验分页参数，0表示使用接口默认值
+func validatePage(index, size int) error {
+	if index < 0 {
+		return &ValidationError{Field: "index", Reason: "must not be negative"}
+	}
+	if size < 0 || size > MaxPageSize {
+		return &ValidationError{Field: "size", Reason: "must be 0 to " + strconv.Itoa(MaxPageSize)}
+	}
+	return nil
+}
+
+// 接口返回的数字可能是字符串，空字符串和null按0处理
+type statNumber int64
+
+func (this *statNumber) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		data = []byte(s)
+	}
+	if len(data) == 0 || string(data) == "null" {
+		*this = 0
+		return nil
+	}
+	n, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil {
+		f, ferr := strconv.ParseFloat(string(data), 64)
+		if ferr != nil {
+			return err
+		}
+		n = int64(f)
+	}
+	*this = statNumber(n)
+	return nil
+}
+
+// 统计数据行的原始格式
+type rawStat struct {
+	VideoId   statNumber `json:"video_id"`
+	VideoName string     `json:"video_name"`
+	Date      string     `json:"date"`
+	Hour      statNumber `json:"hour"`
+	PlayCount statNumber `json:"vv"`
+	Traffic   statNumber `json:"flux"`
+}
+
+func (this *rawStat) date() (time.Time, error) {
+	if this.Date == "" {
+		return time.Time{}, nil
+	}
+	return ParseStatDate(this.Date)
+}
+
+func (this *HourStat) UnmarshalJSON(data []byte) error {
+	raw := rawStat{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	date, err := raw.date()
+	if err != nil {
+		return err
+	}
+	*this = HourStat{
+		VideoId:   int(raw.VideoId),
+		VideoName: raw.VideoName,
+		Date:      date,
+		Hour:      int(raw.Hour),
+		PlayCount: int64(raw.PlayCount),
+		Traffic:   int64(raw.Traffic),
+	}
+	return nil
+}
+
+/**
+ * 该小时的开始时间
+ * @return time.Time
+ */
+func (this *HourStat) Time() time.Time {
+	return this.Date.Add(time.Duration(this.Hour) * time.Hour)
+}
+
+func (this *DateStat) UnmarshalJSON(data []byte) error {
+	raw := rawStat{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	date, err := raw.date()
+	if err != nil {
+		return err
+	}
+	*this = DateStat{
+		VideoId:   int(raw.VideoId),
+		VideoName: raw.VideoName,
+		Date:      date,
+		PlayCount: int64(raw.PlayCount),
+		Traffic:   int64(raw.Traffic),
+	}
+	return nil
+}
+
+func (this *TotalStat) UnmarshalJSON(data []byte) error {
+	raw := rawStat{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return err
+	}
+	date, err := raw.date()
+	if err != nil {
+		return err
+	}
+	*this = TotalStat{
+		Date:      date,
+		PlayCount: int64(raw.PlayCount),
+		Traffic:   int64(raw.Traffic),
+	}
+	return nil
+}
+
+// 按接口格式输出，可以被UnmarshalJSON读回
+func (this HourStat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		VideoId   int    `json:"video_id"`
+		VideoName string `json:"video_name"`
+		Date      string `json:"date"`
+		Hour      int    `json:"hour"`
+		PlayCount int64  `json:"vv"`
+		Traffic   int64  `json:"flux"`
+	}{this.VideoId, this.VideoName, formatStatDate(this.Date), this.Hour, this.PlayCount, this.Traffic})
+}
+
+// 按接口格式输出，可以被UnmarshalJSON读回
+func (this DateStat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		VideoId   int    `json:"video_id"`
+		VideoName string `json:"video_name"`
+		Date      string `json:"date"`
+		PlayCount int64  `json:"vv"`
+		Traffic   int64  `json:"flux"`
+	}{this.VideoId, this.VideoName, formatStatDate(this.Date), this.PlayCount, this.Traffic})
+}
+
+// 按接口格式输出，可以被UnmarshalJSON读回
+func (this TotalStat) MarshalJSON() ([]byte, error) {
+	return json.Marshal(struct {
+		Date      string `json:"date"`
+		PlayCount int64  `json:"vv"`
+		Traffic   int64  `json:"flux"`
+	}{formatStatDate(this.Date), this.PlayCount, this.Traffic})
+}
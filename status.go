@@ -0,0 +1,125 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+/**
+ * 视频状态，取值与接口一致
+ */
+type VideoStatus int
+
+const (
+	// 接口没有返回状态，不是接口取值，不能用于查询
+	UNKNOWN VideoStatus = -1
+	// 全部，仅用于列表查询
+	ALL VideoStatus = 0
+	// 可以正常播放
+	PLAY_OK VideoStatus = 10
+	// 处理失败
+	FAILED VideoStatus = 20
+	// 正在处理过程中
+	WAIT VideoStatus = 30
+)
+
+var videoStatusNames = map[VideoStatus]string{
+	ALL:     "all",
+	PLAY_OK: "play_ok",
+	FAILED:  "failed",
+	WAIT:    "wait",
+}
+
+func (this VideoStatus) String() string {
+	if this == UNKNOWN {
+		return "unknown"
+	}
+	if name, ok := videoStatusNames[this]; ok {
+		return name
+	}
+	return "VideoStatus(" + strconv.Itoa(int(this)) + ")"
+}
+
+/**
+ * 是否为已知状态
+ * @return bool
+ */
+func (this VideoStatus) IsValid() bool {
+	_, ok := videoStatusNames[this]
+	return ok
+}
+
+/**
+ * 是否为处理结束的状态：可以正常播放或处理失败
+ * @return bool
+ */
+func (this VideoStatus) IsTerminal() bool {
+	return this == PLAY_OK || this == FAILED
+}
+
+/**
+ * 是否可以从当前状态变为next：处理中可以变为任意状态，处理结束后状态不再变化
+ * @param  VideoStatus next
+ * @return bool
+ */
+func (this VideoStatus) CanTransitionTo(next VideoStatus) bool {
+	if this == ALL || next == ALL || !this.IsValid() || !next.IsValid() {
+		return false
+	}
+	if this == WAIT {
+		return true
+	}
+	return this == next
+}
+
+// 按接口取值输出数字
+func (this VideoStatus) MarshalJSON() ([]byte, error) {
+	return []byte(strconv.Itoa(int(this))), nil
+}
+
+// 接受数字、数字字符串或状态名称
+func (this *VideoStatus) UnmarshalJSON(data []byte) error {
+	data = bytes.TrimSpace(data)
+	if len(data) > 0 && data[0] == '"' {
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return err
+		}
+		status, err := ParseVideoStatus(s)
+		if err != nil {
+			return err
+		}
+		*this = status
+		return nil
+	}
+	var n int
+	if err := json.Unmarshal(data, &n); err != nil {
+		return err
+	}
+	*this = VideoStatus(n)
+	return nil
+}
+
+/**
+ * 解析视频状态，接受接口取值（如"10"）或状态名称（如"play_ok"）
+ * @param  string s
+ * @return VideoStatus, error
+ */
+func ParseVideoStatus(s string) (VideoStatus, error) {
+	s = strings.TrimSpace(s)
+	if n, err := strconv.Atoi(s); err == nil {
+		if status := VideoStatus(n); status.IsValid() {
+			return status, nil
+		}
+	}
+	name := strings.ToLower(s)
+	for status, v := range videoStatusNames {
+		if v == name {
+			return status, nil
+		}
+	}
+	return 0, errors.New("letv: unknown video status " + strconv.Quote(s))
+}
@@ -0,0 +1,112 @@
+package sdk
+
+import (
+	"io"
+	"sync"
+	"time"
+)
+
+const (
+	// 进度回调最小间隔
+	transferReportInterval = 250 * time.Millisecond
+	// 速度平滑系数，越大越接近瞬时速度
+	transferRateAlpha = 0.3
+)
+
+/**
+ * 客户端上传进度
+ * Total 小于0表示总大小未知，此时 ETA 为-1
+ */
+type TransferStats struct {
+	Sent    int64
+	Total   int64
+	Rate    float64
+	ETA     time.Duration
+	Elapsed time.Duration
+}
+
+/**
+ * 上传进度回调，在写入上传内容的协程中调用，不应阻塞
+ */
+type TransferFunc func(TransferStats)
+
+// 统计已发送字节数、平滑速度和剩余时间
+type transferMeter struct {
+	fn    TransferFunc
+	total int64
+
+	mu       sync.Mutex
+	start    time.Time
+	last     time.Time
+	lastSent int64
+	rate     float64
+}
+
+func newTransferMeter(fn TransferFunc, total int64) *transferMeter {
+	if fn == nil {
+		return nil
+	}
+	now := time.Now()
+	return &transferMeter{fn: fn, total: total, start: now, last: now}
+}
+
+/**
+ * 包装reader，读取时上报进度
+ * @param  io.Reader r
+ * @param  int64 base r在整个文件中的起始位置，分片上传时为分片偏移
+ * @return io.Reader
+ */
+func (this *transferMeter) reader(r io.Reader, base int64) io.Reader {
+	if this == nil {
+		return r
+	}
+	return &transferReader{r: r, meter: this, sent: base}
+}
+
+// 更新已发送字节数，按间隔限流回调，发送完成时总是回调
+func (this *transferMeter) update(sent int64) {
+	this.mu.Lock()
+	now := time.Now()
+	dt := now.Sub(this.last)
+	done := this.total >= 0 && sent >= this.total
+	if dt < transferReportInterval && !done {
+		this.mu.Unlock()
+		return
+	}
+	if dt > 0 && sent >= this.lastSent {
+		rate := float64(sent-this.lastSent) / dt.Seconds()
+		if this.rate == 0 {
+			this.rate = rate
+		} else {
+			this.rate = transferRateAlpha*rate + (1-transferRateAlpha)*this.rate
+		}
+	}
+	this.last = now
+	this.lastSent = sent
+
+	stats := TransferStats{Sent: sent, Total: this.total, Rate: this.rate, ETA: -1, Elapsed: now.Sub(this.start)}
+	if this.total >= 0 {
+		if done {
+			stats.ETA = 0
+		} else if this.rate > 0 {
+			stats.ETA = time.Duration(float64(this.total-sent) / this.rate * float64(time.Second))
+		}
+	}
+	this.mu.Unlock()
+	this.fn(stats)
+}
+
+type transferReader struct {
+	r     io.Reader
+	meter *transferMeter
+	sent  int64
+}
+
+func (this *transferReader) Read(p []byte) (int, error) {
+	n, err := this.r.Read(p)
+	if n > 0 {
+		this.sent += int64(n)
+		this.meter.update(this.sent)
+	}
+	return n, err
+}
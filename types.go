@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+)
+
+//乐视云接口返回结果
+
+/**
+ * 接口统一返回格式
+ * code 状态值：0表示操作成功；其它值表示失败，具体含义见message说明
+ */
+type response struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Data    json.RawMessage `json:"data"`
+	Total   int             `json:"total"`
+}
+
+/**
+ * 视频上传初始化返回结果 (video.upload.init)
+ */
+type UploadInitResult struct {
+	VideoId     int    `json:"video_id"`
+	VideoUnique string `json:"video_unique"`
+	UploadUrl   string `json:"upload_url"`
+	ProgressUrl string `json:"progress_url"`
+	Token       string `json:"token"`
+	UploadType  int    `json:"uploadtype"`
+}
+
+/**
+ * 视频断点续传返回结果 (video.upload.resume)
+ */
+type UploadResumeResult struct {
+	UploadUrl   string `json:"upload_url"`
+	ProgressUrl string `json:"progress_url"`
+	UploadSize  int64  `json:"upload_size"`
+	UploadType  int    `json:"uploadtype"`
+}
+
+/**
+ * 视频上传（Flash方式）返回结果 (video.upload.flash)
+ */
+type FlashUploadResult struct {
+	VideoId     int    `json:"video_id"`
+	VideoUnique string `json:"video_unique"`
+	FlashUrl    string `json:"flash_url"`
+}
+
+/**
+ * 视频信息 (video.get / video.list)
+ */
+type Video struct {
+	VideoId       int         `json:"video_id"`
+	VideoUnique   string      `json:"video_unique"`
+	VideoName     string      `json:"video_name"`
+	VideoDesc     string      `json:"video_desc"`
+	Tag           string      `json:"tag"`
+	Status        VideoStatus `json:"status"`
+	IsPay         int         `json:"is_pay"`
+	Img           string      `json:"img"`
+	VideoDuration int         `json:"video_duration"`
+	InitialSize   int64       `json:"initial_size"`
+	AddTime       string      `json:"add_time"`
+	CompleteTime  string      `json:"complete_time"`
+}
+
+// 没有返回status时为UNKNOWN，避免被当作ALL
+func (this *Video) UnmarshalJSON(data []byte) error {
+	type video Video
+	v := video{Status: UNKNOWN}
+	if err := json.Unmarshal(data, &v); err != nil {
+		return err
+	}
+	*this = Video(v)
+	return nil
+}
+
+/**
+ * 视频列表分页结果 (video.list)
+ */
+type VideoPage struct {
+	Total  int
+	Videos []Video
+}
+
+/**
+ * 视频截图，key为截图尺寸，每种尺寸各有8张图 (image.get)
+ */
+type Images map[string][]string
+
+/**
+ * 视频小时数据 (data.video.hour)
+ */
+type HourStat struct {
+	VideoId   int       `json:"video_id"`
+	VideoName string    `json:"video_name"`
+	Date      time.Time `json:"date"`
+	Hour      int       `json:"hour"`
+	PlayCount int64     `json:"vv"`
+	Traffic   int64     `json:"flux"`
+}
+
+/**
+ * 视频小时数据分页结果
+ */
+type HourStatPage struct {
+	Total int
+	Stats []HourStat
+}
+
+/**
+ * 视频天数据 (data.video.date)
+ */
+type DateStat struct {
+	VideoId   int       `json:"video_id"`
+	VideoName string    `json:"video_name"`
+	Date      time.Time `json:"date"`
+	PlayCount int64     `json:"vv"`
+	Traffic   int64     `json:"flux"`
+}
+
+/**
+ * 视频天数据分页结果
+ */
+type DateStatPage struct {
+	Total int
+	Stats []DateStat
+}
+
+/**
+ * 所有数据 (data.total.date)
+ */
+type TotalStat struct {
+	Date      time.Time `json:"date"`
+	PlayCount int64     `json:"vv"`
+	Traffic   int64     `json:"flux"`
+}
+
+/**
+ * 所有数据分页结果
+ */
+type TotalStatPage struct {
+	Total int
+	Stats []TotalStat
+}
+
+/**
+ * 解析接口返回，统一检查code/message
+ * @param api 接口名称
+ * @param body 接口返回内容
+ * @param data data字段解析目标，为nil时不解析
+ * @return *response, error
+ */
+func decodeResponse(api string, body []byte, data interface{}) (*response, error) {
+	if len(body) == 0 {
+		return nil, &DecodeError{Body: body, Err: ErrEmptyResponse}
+	}
+	resp := &response{}
+	if err := json.Unmarshal(body, resp); err != nil {
+		return nil, &DecodeError{Body: body, Err: err}
+	}
+	if resp.Code != 0 {
+		return resp, &APIError{Api: api, Code: resp.Code, Message: resp.Message}
+	}
+	if data != nil && !isEmptyData(resp.Data) {
+		if err := json.Unmarshal(resp.Data, data); err != nil {
+			return resp, &DecodeError{Body: body, Err: err}
+		}
+	}
+	return resp, nil
+}
+
+// 接口无数据时data可能为null、[]或""
+func isEmptyData(data json.RawMessage) bool {
+	data = bytes.TrimSpace(data)
+	switch string(data) {
+	case "", "null", "[]", "{}", `""`:
+		return true
+	}
+	return false
+}
+
+// 调用接口并解析返回结果
+func (this *LetvCloudV1) callApi(ctx context.Context, api string, params map[interface{}]interface{}, data interface{}) (*response, error) {
+	body, err := this.makeRequest(ctx, api, params)
+	if err != nil {
+		return nil, err
+	}
+	return decodeResponse(api, body, data)
+}
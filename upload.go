@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"context"
+	"io"
+	"io/ioutil"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+)
+
+// 视频上传时form表单中文件的参数名称
+const uploadFieldName = "uploadfile"
+
+/**
+ * 上传本地文件：视频上传初始化后整体上传，file_size根据文件自动填写
+ * @param  context.Context ctx 请求上下文
+ * @param  string video_name 视频名称
+ * @param  string video_file 文件绝对路径
+ * @param  *UploadInitOptions opts 初始化可选参数，可以为nil
+ * @param  TransferFunc progress 进度回调，可以为nil
+ * @return *UploadInitResult, error
+ */
+func (this *LetvCloudV1) UploadFile(ctx context.Context, video_name, video_file string, opts *UploadInitOptions, progress TransferFunc) (*UploadInitResult, error) {
+	fh, err := os.Open(video_file)
+	if err != nil {
+		return nil, err
+	}
+	defer fh.Close()
+
+	info, err := fh.Stat()
+	if err != nil {
+		return nil, err
+	}
+	result, err := this.videoUploadInit_(ctx, video_name, fileInitOptions(opts, info.Size(), UPLOAD_NORMAL))
+	if err != nil {
+		return nil, err
+	}
+	meter := newTransferMeter(progress, info.Size())
+	return result, this.upload(ctx, result.UploadUrl, filepath.Base(video_file), meter.reader(fh, 0), info.Size(), nil)
+}
+
+// 复制初始化参数并填写文件大小和上传方式
+func fileInitOptions(opts *UploadInitOptions, size int64, uploadtype int) UploadInitOptions {
+	o := UploadInitOptions{}
+	if opts != nil {
+		o = *opts
+	}
+	o.FileSize = size
+	o.UploadType = uploadtype
+	return o
+}
+
+/**
+ * 视频上传 (web方式)，从 io.Reader 读取视频内容，不需要落地到本地文件
+ * @param  context.Context ctx 请求上下文
+ * @param  io.Reader r 视频内容
+ * @param  int64 size 视频大小，单位为字节，小于0表示未知
+ * @param  string name 视频文件名
+ * @param  string upload_url 视频上传地址，视频上传时提交地址
+ * @return error
+ */
+func (this *LetvCloudV1) UploadReader(ctx context.Context, r io.Reader, size int64, name, upload_url string) error {
+	return this.UploadReaderWithProgress(ctx, r, size, name, upload_url, nil)
+}
+
+/**
+ * 视频上传 (web方式)，从 io.Reader 读取视频内容，上传过程中回调进度
+ * @param  context.Context ctx 请求上下文
+ * @param  io.Reader r 视频内容
+ * @param  int64 size 视频大小，单位为字节，小于0表示未知
+ * @param  string name 视频文件名
+ * @param  string upload_url 视频上传地址，视频上传时提交地址
+ * @param  TransferFunc progress 进度回调，可以为nil
+ * @return error
+ */
+func (this *LetvCloudV1) UploadReaderWithProgress(ctx context.Context, r io.Reader, size int64, name, upload_url string, progress TransferFunc) error {
+	meter := newTransferMeter(progress, size)
+	return this.upload(ctx, upload_url, name, meter.reader(r, 0), size, nil)
+}
+
+// 上传并检查返回结果
+func (this *LetvCloudV1) upload(ctx context.Context, targetUrl, filename string, r io.Reader, size int64, header http.Header) error {
+	body, err := this.postMultipart(ctx, targetUrl, filename, r, size, header)
+	if err != nil {
+		return err
+	}
+	_, err = decodeResponse("video.upload", body, nil)
+	return err
+}
+
+/**
+ * 以 multipart/form-data 流式上传，边读边发，不在内存中缓存整个文件
+ * @param  context.Context ctx 请求上下文
+ * @param  string targetUrl 上传地址
+ * @param  string filename 文件名
+ * @param  io.Reader r 文件内容
+ * @param  int64 size 文件大小，小于0时使用chunked传输
+ * @param  http.Header header 额外的请求头
+ * @return []byte, error
+ */
+func (this *LetvCloudV1) postMultipart(ctx context.Context, targetUrl, filename string, r io.Reader, size int64, header http.Header) ([]byte, error) {
+	if this.postTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, this.postTimeout)
+		defer cancel()
+	}
+
+	boundary := multipart.NewWriter(ioutil.Discard).Boundary()
+	overhead, contentType, err := multipartOverhead(boundary, filename)
+	if err != nil {
+		return nil, err
+	}
+
+	pr, pw := io.Pipe()
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		bodyWriter := multipart.NewWriter(pw)
+		err := bodyWriter.SetBoundary(boundary)
+		var fileWriter io.Writer
+		if err == nil {
+			fileWriter, err = bodyWriter.CreateFormFile(uploadFieldName, filename)
+		}
+		if err == nil {
+			err = copyBody(fileWriter, &contextReader{ctx: ctx, r: r}, size)
+		}
+		if err == nil {
+			err = bodyWriter.Close()
+		}
+		pw.CloseWithError(err)
+	}()
+	// 请求结束后关闭管道让写入协程退出，并等待其结束，保证返回后不再读取r和回调进度
+	defer func() {
+		pr.CloseWithError(io.ErrClosedPipe)
+		<-done
+	}()
+
+	req, err := http.NewRequestWithContext(ctx, "POST", targetUrl, pr)
+	if err != nil {
+		return nil, err
+	}
+	req.ContentLength = -1
+	if size >= 0 {
+		req.ContentLength = overhead + size
+	}
+	for k, v := range header {
+		req.Header[k] = v
+	}
+	req.Header.Set("Content-Type", contentType)
+	return this.do("POST", req)
+}
+
+// 复制文件内容，size大于等于0时校验实际长度
+func copyBody(w io.Writer, r io.Reader, size int64) error {
+	if size < 0 {
+		_, err := io.Copy(w, r)
+		return err
+	}
+	n, err := io.Copy(w, io.LimitReader(r, size))
+	if err == nil && n != size {
+		err = io.ErrUnexpectedEOF
+	}
+	return err
+}
+
+/**
+ * 计算multipart表单中除文件内容外的字节数，用于预先确定Content-Length
+ * @return int64, string, error 字节数, Content-Type, error
+ */
+func multipartOverhead(boundary, filename string) (int64, string, error) {
+	counter := &countWriter{}
+	bodyWriter := multipart.NewWriter(counter)
+	if err := bodyWriter.SetBoundary(boundary); err != nil {
+		return 0, "", err
+	}
+	if _, err := bodyWriter.CreateFormFile(uploadFieldName, filename); err != nil {
+		return 0, "", err
+	}
+	if err := bodyWriter.Close(); err != nil {
+		return 0, "", err
+	}
+	return counter.n, bodyWriter.FormDataContentType(), nil
+}
+
+// 只计数不保存的Writer
+type countWriter struct {
+	n int64
+}
+
+func (this *countWriter) Write(p []byte) (int, error) {
+	this.n += int64(len(p))
+	return len(p), nil
+}
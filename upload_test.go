@@ -0,0 +1,118 @@
+package sdk
+
+import (
+	"bytes"
+	"context"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 每次等待后读取1字节，记录UploadReader返回后是否还在读取
+type slowReader struct {
+	returned int32
+	late     int32
+}
+
+func (this *slowReader) Read(p []byte) (int, error) {
+	time.Sleep(2 * time.Millisecond)
+	// 返回前仍在进行中的读取也算
+	if atomic.LoadInt32(&this.returned) == 1 {
+		atomic.AddInt32(&this.late, 1)
+	}
+	p[0] = 'x'
+	return 1, nil
+}
+
+func TestUploadReaderStopsReadingOnReturn(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// 不读取请求内容直接返回错误，内容超过256KB时服务端不会先读完请求再返回
+		w.WriteHeader(http.StatusInternalServerError)
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewLetvCloudV1(testUnique, testSecret)
+	r := &slowReader{}
+	err := c.UploadReader(context.Background(), r, 1<<20, "a.mp4", server.URL)
+	atomic.StoreInt32(&r.returned, 1)
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	time.Sleep(50 * time.Millisecond)
+	if n := atomic.LoadInt32(&r.late); n != 0 {
+		t.Errorf("reader was read %d times after UploadReader returned", n)
+	}
+}
+
+func TestUploadReaderStreamsBody(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	var got []byte
+	var contentLength int64
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		contentLength = r.ContentLength
+		file, header, err := r.FormFile(uploadFieldName)
+		if err != nil {
+			t.Errorf("read form file: %v", err)
+			return
+		}
+		if header.Filename != "a.mp4" {
+			t.Errorf("filename = %q", header.Filename)
+		}
+		got, _ = ioutil.ReadAll(file)
+		w.Write([]byte(`{"code":0}`))
+	}))
+	t.Cleanup(server.Close)
+
+	c := NewLetvCloudV1(testUnique, testSecret)
+	var last int64
+	err := c.UploadReaderWithProgress(context.Background(), bytes.NewReader(content), int64(len(content)), "a.mp4", server.URL, func(p TransferStats) {
+		last = p.Sent
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, content) {
+		t.Errorf("uploaded %d bytes, want %d", len(got), len(content))
+	}
+	if contentLength <= int64(len(content)) {
+		t.Errorf("Content-Length = %d, want body size plus multipart overhead", contentLength)
+	}
+	if last != int64(len(content)) {
+		t.Errorf("last progress = %d, want %d", last, len(content))
+	}
+}
+
+func TestUploadFileReportsProgress(t *testing.T) {
+	content := bytes.Repeat([]byte("0123456789"), 1000)
+	path := filepath.Join(t.TempDir(), "a.mp4")
+	if err := ioutil.WriteFile(path, content, 0600); err != nil {
+		t.Fatal(err)
+	}
+	upload, uploadUrl := newFakeUpload(t, func(string, int) (int, string) { return 0, "" })
+	f, c := newFakeApi(t, func(q url.Values) string {
+		return `{"code":0,"data":{"video_id":7,"upload_url":"` + uploadUrl + `","uploadtype":0}}`
+	})
+
+	var last TransferStats
+	result, err := c.UploadFile(context.Background(), "a", path, nil, func(stats TransferStats) { last = stats })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.VideoId != 7 {
+		t.Errorf("video_id = %d", result.VideoId)
+	}
+	if q := f.requests()[0]; q.Get("file_size") != "10000" || q.Get("uploadtype") != "" {
+		t.Errorf("init params = %v", q)
+	}
+	if !bytes.Equal(upload.data[""], content) {
+		t.Errorf("uploaded %d bytes, want %d", len(upload.data[""]), len(content))
+	}
+	if last.Sent != int64(len(content)) || last.Total != int64(len(content)) || last.ETA != 0 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
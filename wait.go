@@ -0,0 +1,169 @@
+package sdk
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"strconv"
+	"time"
+)
+
+const (
+	// 默认首次查询间隔
+	DefaultWaitInterval = 5 * time.Second
+	// 默认最大查询间隔
+	DefaultWaitMaxInterval = time.Minute
+	// 默认间隔增长倍数
+	DefaultWaitMultiplier = 2.0
+	// 默认随机抖动比例
+	DefaultWaitJitter = 0.2
+)
+
+var (
+	// 等待超过WaitOptions.Timeout
+	ErrWaitTimeout = errors.New("letv: timed out waiting for video")
+	// 视频处理失败
+	ErrTranscodeFailed = errors.New("letv: video transcode failed")
+	// 接口返回的视频状态不是处理中也不是处理结束
+	ErrUnexpectedStatus = errors.New("letv: unexpected video status")
+)
+
+/**
+ * 视频处理失败，errors.Is(err, ErrTranscodeFailed) 为true
+ */
+type TranscodeError struct {
+	Video *Video
+}
+
+func (this *TranscodeError) Error() string {
+	return "letv: video " + strconv.Itoa(this.Video.VideoId) + " transcode failed"
+}
+
+func (this *TranscodeError) Is(target error) bool {
+	return target == ErrTranscodeFailed
+}
+
+/**
+ * 视频状态缺失或无法识别，errors.Is(err, ErrUnexpectedStatus) 为true
+ */
+type StatusError struct {
+	Video *Video
+}
+
+func (this *StatusError) Error() string {
+	return "letv: video " + strconv.Itoa(this.Video.VideoId) + " has unexpected status " + this.Video.Status.String()
+}
+
+func (this *StatusError) Is(target error) bool {
+	return target == ErrUnexpectedStatus
+}
+
+/**
+ * WaitForVideo 配置，零值使用默认配置
+ */
+type WaitOptions struct {
+	// 首次查询间隔
+	Interval time.Duration
+	// 最大查询间隔
+	MaxInterval time.Duration
+	// 每次查询后间隔增长倍数
+	Multiplier float64
+	// 随机抖动比例，0-1之间
+	Jitter float64
+	// 最长等待时间，0表示只受ctx控制
+	Timeout time.Duration
+	// 每次查询成功后调用
+	OnPoll func(*Video)
+}
+
+func (this *WaitOptions) withDefaults() WaitOptions {
+	opts := WaitOptions{}
+	if this != nil {
+		opts = *this
+	}
+	if opts.Interval <= 0 {
+		opts.Interval = DefaultWaitInterval
+	}
+	if opts.MaxInterval <= 0 {
+		opts.MaxInterval = DefaultWaitMaxInterval
+	}
+	if opts.Multiplier < 1 {
+		opts.Multiplier = DefaultWaitMultiplier
+	}
+	if opts.Jitter <= 0 || opts.Jitter > 1 {
+		opts.Jitter = DefaultWaitJitter
+	}
+	return opts
+}
+
+// 在间隔上增加随机抖动，避免大量任务同时查询
+func (this *WaitOptions) jitter(d time.Duration) time.Duration {
+	delta := (rand.Float64()*2 - 1) * this.Jitter * float64(d)
+	return d + time.Duration(delta)
+}
+
+/**
+ * 轮询video.get直到视频处理结束
+ * 可以正常播放时返回视频信息；处理失败时返回视频信息和*TranscodeError；状态缺失或无法识别时返回视频信息和*StatusError；
+ * 超时返回最后一次查询的视频信息和ErrWaitTimeout
+ * @param  context.Context ctx 请求上下文
+ * @param  int video_id 视频ID
+ * @param  *WaitOptions opts 轮询配置，可以为nil
+ * @return *Video, error
+ */
+func (this *LetvCloudV1) WaitForVideo(ctx context.Context, video_id int, opts *WaitOptions) (*Video, error) {
+	o := opts.withDefaults()
+	waitCtx := ctx
+	if o.Timeout > 0 {
+		var cancel context.CancelFunc
+		waitCtx, cancel = context.WithTimeout(ctx, o.Timeout)
+		defer cancel()
+	}
+
+	var video *Video
+	interval := o.Interval
+	for {
+		v, err := this.VideoGet(waitCtx, video_id)
+		if err == nil {
+			video = v
+			if o.OnPoll != nil {
+				o.OnPoll(v)
+			}
+			switch v.Status {
+			case PLAY_OK:
+				return v, nil
+			case FAILED:
+				return v, &TranscodeError{Video: v}
+			case WAIT:
+				// 处理中，继续轮询
+			default:
+				return v, &StatusError{Video: v}
+			}
+		} else if !isRetryable(waitCtx, err) {
+			if waitCtx.Err() != nil {
+				return video, waitError(ctx)
+			}
+			return video, err
+		}
+
+		timer := time.NewTimer(o.jitter(interval))
+		select {
+		case <-waitCtx.Done():
+			timer.Stop()
+			return video, waitError(ctx)
+		case <-timer.C:
+		}
+		interval = time.Duration(float64(interval) * o.Multiplier)
+		if interval > o.MaxInterval {
+			interval = o.MaxInterval
+		}
+	}
+}
+
+// 调用方ctx结束时返回ctx的错误，否则为超时
+func waitError(ctx context.Context) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return ErrWaitTimeout
+}
@@ -0,0 +1,409 @@
+package sdk
+
+import (
+	"context"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// 首次同步时默认回溯的时间
+	DefaultStatBackfill = 24 * time.Hour
+	// 每次同步默认重新拉取的最近时间，用于获取延迟到达的数据
+	DefaultStatLookback = 3 * time.Hour
+	// 小时结束后默认等待多久才认为数据完整
+	DefaultStatDelay = 10 * time.Minute
+	// 默认定期同步间隔
+	DefaultStatSyncInterval = 10 * time.Minute
+)
+
+/**
+ * 小时数据本地存储
+ * 小时均为 StatLocation 时区的整点
+ */
+type StatStore interface {
+	// 替换某小时的全部数据，重复写入同一小时时覆盖
+	PutHour(hour time.Time, stats []HourStat) error
+	// 读取[start, end)内的数据，按小时、视频ID排序
+	Hours(start, end time.Time) ([]HourStat, error)
+	// 已完整同步的最后一个小时，没有时返回零值
+	Checkpoint() (time.Time, error)
+	// 保存已完整同步的最后一个小时
+	SaveCheckpoint(hour time.Time) error
+}
+
+/**
+ * 内存存储，进程退出后数据丢失
+ */
+type MemoryStatStore struct {
+	mu         sync.Mutex
+	hours      map[int64][]HourStat
+	checkpoint time.Time
+}
+
+/**
+ * 创建内存存储
+ * @return *MemoryStatStore
+ */
+func NewMemoryStatStore() *MemoryStatStore {
+	return &MemoryStatStore{hours: make(map[int64][]HourStat)}
+}
+
+func (this *MemoryStatStore) PutHour(hour time.Time, stats []HourStat) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.hours[hour.Unix()] = append([]HourStat(nil), stats...)
+	return nil
+}
+
+func (this *MemoryStatStore) Hours(start, end time.Time) ([]HourStat, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	stats := make([]HourStat, 0)
+	for key, rows := range this.hours {
+		if key >= start.Unix() && key < end.Unix() {
+			stats = append(stats, rows...)
+		}
+	}
+	sortHourStats(stats)
+	return stats, nil
+}
+
+func (this *MemoryStatStore) Checkpoint() (time.Time, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return this.checkpoint, nil
+}
+
+func (this *MemoryStatStore) SaveCheckpoint(hour time.Time) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	this.checkpoint = hour
+	return nil
+}
+
+/**
+ * 文件存储，每天一个JSON文件，另有一个文件记录同步进度
+ */
+type FileStatStore struct {
+	dir string
+	mu  sync.Mutex
+}
+
+const (
+	// 同步进度文件名
+	statCheckpointFile = "checkpoint.json"
+	// 天数据文件后缀
+	statFileSuffix = ".json"
+)
+
+/**
+ * 创建文件存储，目录不存在时自动创建，并删除上次崩溃遗留的临时文件
+ * @param  string dir 存储目录
+ * @return *FileStatStore, error
+ */
+func NewFileStatStore(dir string) (*FileStatStore, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	if err := removeStaleTmpFiles(dir); err != nil {
+		return nil, err
+	}
+	return &FileStatStore{dir: dir}, nil
+}
+
+func (this *FileStatStore) path(day time.Time) string {
+	return filepath.Join(this.dir, formatStatDate(day)+statFileSuffix)
+}
+
+func (this *FileStatStore) PutHour(hour time.Time, stats []HourStat) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	path := this.path(hour)
+	day, err := readStatFile(path)
+	if err != nil {
+		return err
+	}
+	day[strconv.Itoa(hour.In(StatLocation).Hour())] = stats
+	return writeJSONFile(path, day)
+}
+
+func (this *FileStatStore) Hours(start, end time.Time) ([]HourStat, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	stats := make([]HourStat, 0)
+	for d := StatDay(start); d.Before(end); d = d.AddDate(0, 0, 1) {
+		day, err := readStatFile(this.path(d))
+		if err != nil {
+			return nil, err
+		}
+		for h, rows := range day {
+			n, err := strconv.Atoi(h)
+			if err != nil {
+				continue
+			}
+			hour := d.Add(time.Duration(n) * time.Hour)
+			if !hour.Before(start) && hour.Before(end) {
+				stats = append(stats, rows...)
+			}
+		}
+	}
+	sortHourStats(stats)
+	return stats, nil
+}
+
+func (this *FileStatStore) Checkpoint() (time.Time, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	data, err := ioutil.ReadFile(filepath.Join(this.dir, statCheckpointFile))
+	if os.IsNotExist(err) {
+		return time.Time{}, nil
+	}
+	if err != nil {
+		return time.Time{}, err
+	}
+	checkpoint := struct {
+		Hour time.Time `json:"hour"`
+	}{}
+	if err := json.Unmarshal(data, &checkpoint); err != nil {
+		return time.Time{}, err
+	}
+	return checkpoint.Hour.In(StatLocation), nil
+}
+
+func (this *FileStatStore) SaveCheckpoint(hour time.Time) error {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+	return writeJSONFile(filepath.Join(this.dir, statCheckpointFile), struct {
+		Hour time.Time `json:"hour"`
+	}{hour})
+}
+
+// 文件不存在时返回空数据，key为小时
+func readStatFile(path string) (map[string][]HourStat, error) {
+	day := make(map[string][]HourStat)
+	data, err := ioutil.ReadFile(path)
+	if os.IsNotExist(err) {
+		return day, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, &day); err != nil {
+		return nil, err
+	}
+	return day, nil
+}
+
+// 按小时、视频ID排序
+func sortHourStats(stats []HourStat) {
+	sort.SliceStable(stats, func(i, j int) bool {
+		ti, tj := stats[i].Time(), stats[j].Time()
+		if !ti.Equal(tj) {
+			return ti.Before(tj)
+		}
+		return stats[i].VideoId < stats[j].VideoId
+	})
+}
+
+/**
+ * StatSync 配置，零值使用默认配置
+ */
+type StatSyncOptions struct {
+	// 存储为空时从该时间所在的小时开始同步，默认为DefaultStatBackfill之前
+	Start time.Time
+	// 每次同步重新拉取的最近时间
+	Lookback time.Duration
+	// 小时结束后等待多久才同步该小时
+	Delay time.Duration
+	// 同时拉取的小时数，默认为DefaultBulkConcurrency
+	Concurrency int
+	// 每个小时写入存储后调用，可能在多个协程中同时调用
+	OnHour func(hour time.Time, rows int)
+}
+
+func (this *StatSyncOptions) withDefaults() StatSyncOptions {
+	opts := StatSyncOptions{}
+	if this != nil {
+		opts = *this
+	}
+	if opts.Lookback < 0 {
+		opts.Lookback = 0
+	} else if opts.Lookback == 0 {
+		opts.Lookback = DefaultStatLookback
+	}
+	if opts.Delay <= 0 {
+		opts.Delay = DefaultStatDelay
+	}
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = DefaultBulkConcurrency
+	}
+	return opts
+}
+
+/**
+ * 一次同步的结果
+ */
+type StatSyncResult struct {
+	// 拉取的第一个和最后一个小时，没有拉取时为零值
+	From time.Time
+	To   time.Time
+	// 拉取的小时数和数据条数
+	Hours int
+	Rows  int
+	// 同步后的进度
+	Checkpoint time.Time
+}
+
+/**
+ * 将data.video.hour增量同步到本地存储
+ * 每次同步从上次完整同步的小时之后补齐到最近一个完整的小时，每完成一个小时保存一次进度，
+ * 中断后从进度处继续；同时重新拉取进度之前Lookback内的小时，以获取延迟到达的数据
+ *
+ *	store, _ := sdk.NewFileStatStore("stats")
+ *	s := client.NewStatSync(store, nil)
+ *	result, err := s.Sync(ctx)
+ */
+type StatSync struct {
+	client *LetvCloudV1
+	store  StatStore
+	opts   StatSyncOptions
+	mu     sync.Mutex
+}
+
+/**
+ * 创建增量同步
+ * @param  StatStore store 为nil时使用内存存储
+ * @param  *StatSyncOptions opts 可以为nil
+ * @return *StatSync
+ */
+func (this *LetvCloudV1) NewStatSync(store StatStore, opts *StatSyncOptions) *StatSync {
+	if store == nil {
+		store = NewMemoryStatStore()
+	}
+	return &StatSync{client: this, store: store, opts: opts.withDefaults()}
+}
+
+/**
+ * 同步一次，出错时已完成的小时仍会保存进度
+ * @param  context.Context ctx 请求上下文
+ * @return *StatSyncResult, error
+ */
+func (this *StatSync) Sync(ctx context.Context) (*StatSyncResult, error) {
+	this.mu.Lock()
+	defer this.mu.Unlock()
+
+	checkpoint, err := this.store.Checkpoint()
+	if err != nil {
+		return nil, err
+	}
+	result := &StatSyncResult{Checkpoint: checkpoint}
+	last := statHour(time.Now().Add(-this.opts.Delay)).Add(-time.Hour)
+
+	var from time.Time
+	if checkpoint.IsZero() {
+		from = this.opts.Start
+		if from.IsZero() {
+			from = last.Add(-DefaultStatBackfill + time.Hour)
+		}
+		from = statHour(from)
+	} else {
+		checkpoint = statHour(checkpoint)
+		from = checkpoint.Add(time.Hour).Add(-this.opts.Lookback)
+	}
+
+	hours := make([]time.Time, 0)
+	for h := from; !h.After(last); h = h.Add(time.Hour) {
+		hours = append(hours, h)
+	}
+	if len(hours) > 0 {
+		result.From, result.To = hours[0], hours[len(hours)-1]
+	}
+
+	// 每批并发拉取，进度只推进到连续成功的小时
+	for start := 0; start < len(hours); start += this.opts.Concurrency {
+		end := start + this.opts.Concurrency
+		if end > len(hours) {
+			end = len(hours)
+		}
+		batch := hours[start:end]
+		rows := make([]int, len(batch))
+		errs := make([]error, len(batch))
+		runBulk(len(batch), this.opts.Concurrency, func(i int) {
+			rows[i], errs[i] = this.syncHour(ctx, batch[i])
+		})
+		for i, hour := range batch {
+			if errs[i] != nil {
+				return result, errs[i]
+			}
+			result.Hours++
+			result.Rows += rows[i]
+			if hour.After(result.Checkpoint) {
+				if err := this.store.SaveCheckpoint(hour); err != nil {
+					return result, err
+				}
+				result.Checkpoint = hour
+			}
+		}
+	}
+	return result, nil
+}
+
+/**
+ * 按interval定期同步，直到ctx结束
+ * @param  context.Context ctx
+ * @param  time.Duration interval 小于等于0时使用DefaultStatSyncInterval
+ * @param  func(*StatSyncResult, error) onSync 每次同步后调用，可以为nil
+ */
+func (this *StatSync) Run(ctx context.Context, interval time.Duration, onSync func(*StatSyncResult, error)) {
+	if interval <= 0 {
+		interval = DefaultStatSyncInterval
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		result, err := this.Sync(ctx)
+		if onSync != nil {
+			onSync(result, err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// 拉取一个小时的全部数据并写入存储
+func (this *StatSync) syncHour(ctx context.Context, hour time.Time) (int, error) {
+	stats := make([]HourStat, 0)
+	it := this.client.ListHourStats(ctx, DataVideoHourRequest{Date: StatDay(hour), Hour: Int(hour.Hour())})
+	for it.Next() {
+		// 接口可能不返回日期和小时
+		stat := it.Stat()
+		stat.Date, stat.Hour = StatDay(hour), hour.Hour()
+		stats = append(stats, stat)
+	}
+	if err := it.Err(); err != nil {
+		return 0, err
+	}
+	if err := this.store.PutHour(hour, stats); err != nil {
+		return 0, err
+	}
+	if this.opts.OnHour != nil {
+		this.opts.OnHour(hour, len(stats))
+	}
+	return len(stats), nil
+}
+
+// StatLocation 时区的整点
+func statHour(t time.Time) time.Time {
+	t = t.In(StatLocation)
+	return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, StatLocation)
+}
@@ -0,0 +1,195 @@
+package sdk
+
+import (
+	"context"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"testing"
+	"time"
+)
+
+// 模拟data.video.hour，记录请求的小时，failHour返回true时该小时请求失败
+func newFakeHourApi(t *testing.T, failHour func(hour time.Time) bool) (*LetvCloudV1, func() []time.Time) {
+	var mu sync.Mutex
+	hours := make([]time.Time, 0)
+	_, c := newFakeApi(t, func(q url.Values) string {
+		date, err := ParseStatDate(q.Get("date"))
+		if err != nil {
+			t.Errorf("bad date %q", q.Get("date"))
+		}
+		h, _ := strconv.Atoi(q.Get("hour"))
+		hour := date.Add(time.Duration(h) * time.Hour)
+		mu.Lock()
+		hours = append(hours, hour)
+		mu.Unlock()
+		if failHour != nil && failHour(hour) {
+			return `{"code":7,"message":"busy"}`
+		}
+		return `{"code":0,"total":1,"data":[{"video_id":5,"vv":` + strconv.Itoa(h) + `}]}`
+	})
+	return c, func() []time.Time {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]time.Time(nil), hours...)
+	}
+}
+
+// Sync认为的最后一个完整小时
+func lastSyncHour(delay time.Duration) time.Time {
+	return statHour(time.Now().Add(-delay)).Add(-time.Hour)
+}
+
+func TestStatSyncCheckpointAndLookback(t *testing.T) {
+	tests := []struct {
+		name string
+		// 相对最后一个完整小时的偏移，nil表示存储为空
+		checkpoint *int
+		start      int
+		lookback   time.Duration
+		// 应拉取的小时，相对最后一个完整小时
+		wantHours      []int
+		wantCheckpoint int
+	}{
+		{name: "first sync from Start", start: -2, wantHours: []int{-2, -1, 0}, wantCheckpoint: 0},
+		{name: "backfill gap with lookback", checkpoint: Int(-3), lookback: 2 * time.Hour, wantHours: []int{-4, -3, -2, -1, 0}, wantCheckpoint: 0},
+		{name: "up to date refetches trailing window", checkpoint: Int(0), lookback: 3 * time.Hour, wantHours: []int{-2, -1, 0}, wantCheckpoint: 0},
+		{name: "up to date without lookback", checkpoint: Int(0), lookback: -1, wantHours: []int{}, wantCheckpoint: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, fetched := newFakeHourApi(t, nil)
+			last := lastSyncHour(DefaultStatDelay)
+			store := NewMemoryStatStore()
+			if tt.checkpoint != nil {
+				store.SaveCheckpoint(last.Add(time.Duration(*tt.checkpoint) * time.Hour))
+			}
+			s := c.NewStatSync(store, &StatSyncOptions{
+				Start:       last.Add(time.Duration(tt.start) * time.Hour),
+				Lookback:    tt.lookback,
+				Concurrency: 2,
+			})
+			result, err := s.Sync(context.Background())
+			if !lastSyncHour(DefaultStatDelay).Equal(last) {
+				t.Skip("crossed an hour boundary")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			got := fetched()
+			if len(got) != len(tt.wantHours) || result.Hours != len(tt.wantHours) || result.Rows != len(tt.wantHours) {
+				t.Fatalf("fetched %v (result %+v), want offsets %v", got, result, tt.wantHours)
+			}
+			seen := make(map[int64]bool)
+			for _, h := range got {
+				seen[h.Unix()] = true
+			}
+			for _, off := range tt.wantHours {
+				if h := last.Add(time.Duration(off) * time.Hour); !seen[h.Unix()] {
+					t.Errorf("hour %v not fetched", h)
+				}
+			}
+			checkpoint, _ := store.Checkpoint()
+			if want := last.Add(time.Duration(tt.wantCheckpoint) * time.Hour); !checkpoint.Equal(want) || !result.Checkpoint.Equal(want) {
+				t.Errorf("checkpoint = %v (result %v), want %v", checkpoint, result.Checkpoint, want)
+			}
+		})
+	}
+}
+
+func TestStatSyncResumesAfterFailure(t *testing.T) {
+	last := lastSyncHour(DefaultStatDelay)
+	failing := last.Add(-2 * time.Hour)
+	fail := true
+	c, fetched := newFakeHourApi(t, func(hour time.Time) bool {
+		return fail && hour.Equal(failing)
+	})
+	store, err := NewFileStatStore(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := c.NewStatSync(store, &StatSyncOptions{Start: last.Add(-4 * time.Hour), Lookback: -1, Concurrency: 1})
+
+	result, err := s.Sync(context.Background())
+	if err == nil {
+		t.Fatal("expected error")
+	}
+	if !lastSyncHour(DefaultStatDelay).Equal(last) {
+		t.Skip("crossed an hour boundary")
+	}
+	// 失败的小时之前的进度已保存
+	if want := failing.Add(-time.Hour); !result.Checkpoint.Equal(want) {
+		t.Errorf("checkpoint after failure = %v, want %v", result.Checkpoint, want)
+	}
+	if checkpoint, _ := store.Checkpoint(); !checkpoint.Equal(failing.Add(-time.Hour)) {
+		t.Errorf("stored checkpoint = %v", checkpoint)
+	}
+
+	fail = false
+	before := len(fetched())
+	result, err = s.Sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.From.Equal(failing) || !result.To.Equal(last) || result.Hours != 3 {
+		t.Errorf("resume result = %+v, want %v..%v", result, failing, last)
+	}
+	if n := len(fetched()) - before; n != 3 {
+		t.Errorf("resume fetched %d hours, want 3", n)
+	}
+
+	rows, err := store.Hours(last.Add(-4*time.Hour), last.Add(time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 5 {
+		t.Fatalf("stored %d rows, want 5", len(rows))
+	}
+	for i, row := range rows {
+		want := last.Add(time.Duration(i-4) * time.Hour)
+		if !row.Time().Equal(want) || row.PlayCount != int64(want.Hour()) {
+			t.Errorf("row %d = %+v at %v, want hour %v", i, row, row.Time(), want)
+		}
+	}
+}
+
+func TestFileStatStoreRemovesStaleTmpFiles(t *testing.T) {
+	dir := t.TempDir()
+	stale := filepath.Join(dir, "2016-01-02.json"+tmpFileSuffix)
+	fresh := filepath.Join(dir, "2016-01-03.json"+tmpFileSuffix)
+	for _, path := range []string{stale, fresh} {
+		if err := ioutil.WriteFile(path, []byte("{"), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	old := time.Now().Add(-2 * tmpFileMaxAge)
+	os.Chtimes(stale, old, old)
+
+	store, err := NewFileStatStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(stale); !os.IsNotExist(err) {
+		t.Errorf("stale temp file not removed: %v", err)
+	}
+	if _, err := os.Stat(fresh); err != nil {
+		t.Errorf("fresh temp file removed: %v", err)
+	}
+
+	hour := StatDate(2016, 1, 2).Add(3 * time.Hour)
+	if err := store.PutHour(hour, []HourStat{{Date: StatDate(2016, 1, 2), Hour: 3, VideoId: 5, PlayCount: 9}}); err != nil {
+		t.Fatal(err)
+	}
+	rows, err := store.Hours(hour, hour.Add(time.Hour))
+	if err != nil || len(rows) != 1 || rows[0].PlayCount != 9 {
+		t.Errorf("rows = %+v, err = %v", rows, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "2016-01-02.json"+tmpFileSuffix)); !os.IsNotExist(err) {
+		t.Errorf("temp file left after write: %v", err)
+	}
+}